@@ -0,0 +1,109 @@
+package csvutils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BindReport describes how a header row binds to the fields of a record type.
+type BindReport struct {
+	// UnknownColumns are the header cells that no field binds to, in header order.
+	UnknownColumns []string
+	// UnboundFields are the column names of fields without a header cell or a default.
+	UnboundFields []string
+	// DuplicateHeaders are the header cells that occur more than once. Only the last
+	// occurrence is bound.
+	DuplicateHeaders []string
+	// DefaultedFields are the column names of fields without a header cell that fall back
+	// to their `default` tag.
+	DefaultedFields []string
+}
+
+// WithStrictHeaders makes reading fail before any record is processed when the header
+// has unknown or duplicate columns, or a field without a default has no column.
+func WithStrictHeaders() func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.strictHeaders = true
+	}
+}
+
+// Bind matches header against the fields of recordType, a pointer to a struct, with the
+// same options ReadCSV would use, and reports the result without reading any records.
+func Bind(header []string, recordType interface{}, options ...func(*csvOptions)) (*BindReport, error) {
+	opts := newCsvOptions(options)
+	elemType, err := recordElemType(recordType)
+	if err != nil {
+		return nil, err
+	}
+	fieldInfo, err := bindHeader(header, elemType, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBindReport(header, fieldInfo, opts), nil
+}
+
+func newBindReport(header []string, infos []fieldInfo, opts *csvOptions) *BindReport {
+	report := &BindReport{}
+	bound := make([]bool, len(header))
+	walkLeaves(infos, func(info fieldInfo) {
+		switch {
+		case info.columnIndex >= len(bound):
+			// Bound by position past the end of the header.
+		case info.columnIndex >= 0:
+			bound[info.columnIndex] = true
+		case info.defaultValue != "":
+			report.DefaultedFields = append(report.DefaultedFields, info.columnName)
+		default:
+			report.UnboundFields = append(report.UnboundFields, info.columnName)
+		}
+	})
+	boundKeys := make(map[string]bool, len(header))
+	for i, cell := range header {
+		if bound[i] {
+			boundKeys[opts.headerMatcher(cell)] = true
+		}
+	}
+	seen := make(map[string]bool, len(header))
+	for _, cell := range header {
+		key := opts.headerMatcher(cell)
+		if seen[key] {
+			report.DuplicateHeaders = append(report.DuplicateHeaders, cell)
+		}
+		seen[key] = true
+		if !boundKeys[key] {
+			report.UnknownColumns = append(report.UnknownColumns, cell)
+		}
+	}
+	return report
+}
+
+// Err returns an error describing the unknown columns, unbound fields and duplicate
+// headers, or nil if there are none. Defaulted fields are not an error.
+func (r *BindReport) Err() error {
+	var errs []error
+	if len(r.UnknownColumns) > 0 {
+		errs = append(errs, fmt.Errorf("unknown columns: %s", strings.Join(r.UnknownColumns, ", ")))
+	}
+	if len(r.UnboundFields) > 0 {
+		errs = append(errs, fmt.Errorf("missing columns: %s", strings.Join(r.UnboundFields, ", ")))
+	}
+	if len(r.DuplicateHeaders) > 0 {
+		errs = append(errs, fmt.Errorf("duplicate headers: %s", strings.Join(r.DuplicateHeaders, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+// walkLeaves calls fn for every field bound to a single column, including the fields of
+// repeated group elements.
+func walkLeaves(infos []fieldInfo, fn func(fieldInfo)) {
+	for _, info := range infos {
+		if info.elements != nil {
+			for _, element := range info.elements {
+				walkLeaves(element, fn)
+			}
+			continue
+		}
+		fn(info)
+	}
+}
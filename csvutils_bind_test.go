@@ -0,0 +1,71 @@
+package csvutils_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+type Employee struct {
+	Name  string `csv:"name"`
+	Team  string `csv:"team" default:"platform"`
+	Email string `csv:"email"`
+}
+
+func TestBind(t *testing.T) {
+	report, err := csvutils.Bind([]string{"name", "Email", "salary", "name"}, &Employee{})
+	if err != nil {
+		t.Fatalf("Bind returned error: %v", err)
+	}
+	expected := &csvutils.BindReport{
+		UnknownColumns:   []string{"Email", "salary"},
+		UnboundFields:    []string{"email"},
+		DuplicateHeaders: []string{"name"},
+		DefaultedFields:  []string{"team"},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("Report does not match expected. Got: %+v, Expected: %+v", report, expected)
+	}
+
+	// The header matcher option is honoured
+	report, err = csvutils.Bind([]string{"name", "Email"}, &Employee{}, csvutils.WithHeaderMatcher(csvutils.MatchCaseInsensitive))
+	if err != nil {
+		t.Fatalf("Bind returned error: %v", err)
+	}
+	if err := report.Err(); err != nil {
+		t.Errorf("Expected a clean report, got: %v", err)
+	}
+}
+
+func TestWithStrictHeaders(t *testing.T) {
+	handled := 0
+	handler := csvutils.WithHandler(func(record interface{}) error {
+		handled++
+		return nil
+	})
+
+	// A missing column fails before any record is handled
+	err := csvutils.ReadCSVFromReader(strings.NewReader("name,salary\nann,10\n"), &Employee{}, handler, csvutils.WithStrictHeaders())
+	if err == nil {
+		t.Fatal("Expected strict header error, got nil")
+	}
+	for _, part := range []string{"unknown columns: salary", "missing columns: email"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("Expected error to contain %q, got: %v", part, err)
+		}
+	}
+	if handled != 0 {
+		t.Errorf("Expected no records to be handled, got %d", handled)
+	}
+
+	// Defaulted fields are allowed
+	err = csvutils.ReadCSVFromReader(strings.NewReader("name,email\nann,ann@example.com\n"), &Employee{}, handler, csvutils.WithStrictHeaders())
+	if err != nil {
+		t.Fatalf("ReadCSVFromReader returned error: %v", err)
+	}
+	if handled != 1 {
+		t.Errorf("Expected 1 record to be handled, got %d", handled)
+	}
+}
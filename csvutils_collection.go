@@ -0,0 +1,128 @@
+package csvutils
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultElementSeparator  = ";"
+	defaultKeyValueSeparator = "="
+)
+
+// elementSeparator returns the separator between slice elements or map entries within
+// a single cell, taken from the `sep` tag.
+func elementSeparator(field reflect.StructField) string {
+	if sep := field.Tag.Get("sep"); sep != "" {
+		return sep
+	}
+	return defaultElementSeparator
+}
+
+// keyValueSeparator returns the separator between a map key and its value, taken from
+// the `kvsep` tag.
+func keyValueSeparator(field reflect.StructField) string {
+	if sep := field.Tag.Get("kvsep"); sep != "" {
+		return sep
+	}
+	return defaultKeyValueSeparator
+}
+
+// getSliceSetter decodes a cell such as "a;b;c" into a slice, converting every element
+// with the setter of the element type.
+func getSliceSetter(fieldType reflect.Type, field reflect.StructField, opts *csvOptions) (func(reflect.Value, string) error, error) {
+	elemSetter, err := getFieldSetter(fieldType.Elem(), field, opts)
+	if err != nil {
+		return nil, err
+	}
+	sep := elementSeparator(field)
+	return func(v reflect.Value, s string) error {
+		if s == "" {
+			v.Set(reflect.Zero(fieldType))
+			return nil
+		}
+		parts := strings.Split(s, sep)
+		slice := reflect.MakeSlice(fieldType, len(parts), len(parts))
+		for i, part := range parts {
+			if err := elemSetter(slice.Index(i), part); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		v.Set(slice)
+		return nil
+	}, nil
+}
+
+// getMapSetter decodes a cell such as "k=v;k2=v2" into a map, converting keys and values
+// with the setters of their types.
+func getMapSetter(fieldType reflect.Type, field reflect.StructField, opts *csvOptions) (func(reflect.Value, string) error, error) {
+	keySetter, err := getFieldSetter(fieldType.Key(), field, opts)
+	if err != nil {
+		return nil, err
+	}
+	valueSetter, err := getFieldSetter(fieldType.Elem(), field, opts)
+	if err != nil {
+		return nil, err
+	}
+	sep, kvSep := elementSeparator(field), keyValueSeparator(field)
+	return func(v reflect.Value, s string) error {
+		if s == "" {
+			v.Set(reflect.Zero(fieldType))
+			return nil
+		}
+		entries := strings.Split(s, sep)
+		m := reflect.MakeMapWithSize(fieldType, len(entries))
+		for _, entry := range entries {
+			key, value, ok := strings.Cut(entry, kvSep)
+			if !ok {
+				return fmt.Errorf("map entry %q has no %q separator", entry, kvSep)
+			}
+			keyValue := reflect.New(fieldType.Key()).Elem()
+			if err := keySetter(keyValue, key); err != nil {
+				return fmt.Errorf("map key %q: %w", key, err)
+			}
+			elemValue := reflect.New(fieldType.Elem()).Elem()
+			if err := valueSetter(elemValue, value); err != nil {
+				return fmt.Errorf("map value for key %q: %w", key, err)
+			}
+			m.SetMapIndex(keyValue, elemValue)
+		}
+		v.Set(m)
+		return nil
+	}, nil
+}
+
+func formatSlice(v reflect.Value, field reflect.StructField, column string, opts *csvOptions) (string, error) {
+	parts := make([]string, v.Len())
+	for i := range parts {
+		elem := v.Index(i)
+		part, err := formatField(elem, elem.Type(), field, column, opts)
+		if err != nil {
+			return "", fmt.Errorf("element %d: %w", i, err)
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, elementSeparator(field)), nil
+}
+
+// formatMap writes the entries of a map sorted by their formatted key.
+func formatMap(v reflect.Value, field reflect.StructField, column string, opts *csvOptions) (string, error) {
+	kvSep := keyValueSeparator(field)
+	entries := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := formatField(iter.Key(), iter.Key().Type(), field, column, opts)
+		if err != nil {
+			return "", fmt.Errorf("map key: %w", err)
+		}
+		value, err := formatField(iter.Value(), iter.Value().Type(), field, column, opts)
+		if err != nil {
+			return "", fmt.Errorf("map value for key %q: %w", key, err)
+		}
+		entries = append(entries, key+kvSep+value)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, elementSeparator(field)), nil
+}
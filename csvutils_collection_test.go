@@ -0,0 +1,77 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+type Product struct {
+	SKU        string             `csv:"sku"`
+	Categories []string           `csv:"categories" sep:"|"`
+	Scores     []int              `csv:"scores"`
+	Attributes map[string]string  `csv:"attributes"`
+	Stock      map[string]float64 `csv:"stock" sep:"," kvsep:":"`
+}
+
+func TestCollectionFields_RoundTrip(t *testing.T) {
+	products := []Product{
+		{
+			SKU:        "sku-1",
+			Categories: []string{"garden", "tools"},
+			Scores:     []int{4, 5, 3},
+			Attributes: map[string]string{"color": "green", "size": "L"},
+			Stock:      map[string]float64{"berlin": 2.5, "paris": 10},
+		},
+		{SKU: "sku-2"},
+	}
+
+	// Write the records
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Product](&buf)
+	if err := encoder.EncodeAll(products); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "sku,categories,scores,attributes,stock\n" +
+		"sku-1,garden|tools,4;5;3,color=green;size=L,\"berlin:2.5,paris:10\"\n" +
+		"sku-2,,,,\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Read them back
+	records, err := csvutils.ReadAll[Product](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if !reflect.DeepEqual(records, products) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, products)
+	}
+}
+
+func TestCollectionFields_InvalidElement(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{name: "slice element", data: "sku,scores\nsku-1,4;x\n", expected: "element 1"},
+		{name: "map entry", data: "sku,attributes\nsku-1,color\n", expected: `map entry "color"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := csvutils.ReadAll[Product](strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected an error containing %q, got: %v", tt.expected, err)
+			}
+		})
+	}
+}
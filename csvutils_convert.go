@@ -0,0 +1,136 @@
+package csvutils
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Converter parses and formats the values of a single type. Either function may be nil,
+// in which case the built-in conversion is used for that direction.
+type Converter struct {
+	Parse  func(s string) (interface{}, error)
+	Format func(v interface{}) (string, error)
+}
+
+// Converters maps types to the Converter used for fields of that type. Converters are
+// consulted before the built-in conversions, so they can also override how types such as
+// float64 are formatted. A Converters value is safe for concurrent use.
+type Converters struct {
+	mu         sync.RWMutex
+	converters map[reflect.Type]Converter
+}
+
+// GlobalConverters is consulted by every reader and writer after the converters passed
+// with WithConverters.
+var GlobalConverters = NewConverters()
+
+// NewConverters returns an empty converter registry.
+func NewConverters() *Converters {
+	return &Converters{converters: make(map[reflect.Type]Converter)}
+}
+
+// Register sets the converter used for fields of type t. Registering a pointer type such
+// as *big.Int also applies to fields of the element type.
+func (c *Converters) Register(t reflect.Type, converter Converter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.converters[t] = converter
+}
+
+// RegisterConverter registers type-safe parse and format functions for T in c. Either
+// function may be nil.
+func RegisterConverter[T any](c *Converters, parse func(string) (T, error), format func(T) (string, error)) {
+	var converter Converter
+	if parse != nil {
+		converter.Parse = func(s string) (interface{}, error) {
+			return parse(s)
+		}
+	}
+	if format != nil {
+		converter.Format = func(v interface{}) (string, error) {
+			return format(v.(T))
+		}
+	}
+	c.Register(reflect.TypeOf((*T)(nil)).Elem(), converter)
+}
+
+// WithConverters sets the converters used in addition to GlobalConverters.
+func WithConverters(converters *Converters) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.converters = converters
+	}
+}
+
+func (c *Converters) get(t reflect.Type) (Converter, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	converter, ok := c.converters[t]
+	return converter, ok
+}
+
+// lookupConverter finds the converter for fieldType, or for a pointer to it, in the
+// reader or writer converters first and in GlobalConverters second.
+func lookupConverter(fieldType reflect.Type, opts *csvOptions) (converter Converter, viaPointer bool, ok bool) {
+	for _, registry := range []*Converters{opts.converters, GlobalConverters} {
+		if registry == nil {
+			continue
+		}
+		if converter, ok := registry.get(fieldType); ok {
+			return converter, false, true
+		}
+		if converter, ok := registry.get(reflect.PointerTo(fieldType)); ok {
+			return converter, true, true
+		}
+	}
+	return Converter{}, false, false
+}
+
+// hasConverter reports whether a converter for fieldType handles the direction of opts:
+// Parse for readers and Format for writers.
+func hasConverter(fieldType reflect.Type, opts *csvOptions) bool {
+	converter, _, ok := lookupConverter(fieldType, opts)
+	if opts.writing {
+		return ok && converter.Format != nil
+	}
+	return ok && converter.Parse != nil
+}
+
+func getConverterSetter(fieldType reflect.Type, opts *csvOptions) func(reflect.Value, string) error {
+	converter, viaPointer, ok := lookupConverter(fieldType, opts)
+	if !ok || converter.Parse == nil {
+		return nil
+	}
+	return func(v reflect.Value, s string) error {
+		parsed, err := converter.Parse(s)
+		if err != nil {
+			return err
+		}
+		value := reflect.ValueOf(parsed)
+		if viaPointer {
+			if !value.IsValid() || value.IsNil() {
+				v.Set(reflect.Zero(fieldType))
+				return nil
+			}
+			value = value.Elem()
+		}
+		if !value.IsValid() || !value.Type().AssignableTo(fieldType) {
+			return fmt.Errorf("converter returned %T, expected %v", parsed, fieldType)
+		}
+		v.Set(value)
+		return nil
+	}
+}
+
+func getConverterFormatter(fieldType reflect.Type, opts *csvOptions) func(reflect.Value) (string, error) {
+	converter, viaPointer, ok := lookupConverter(fieldType, opts)
+	if !ok || converter.Format == nil {
+		return nil
+	}
+	return func(v reflect.Value) (string, error) {
+		if viaPointer {
+			return converter.Format(addressOf(v).Interface())
+		}
+		return converter.Format(v.Interface())
+	}
+}
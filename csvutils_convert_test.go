@@ -0,0 +1,147 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+type Host struct {
+	Name     string   `csv:"name"`
+	IP       net.IP   `csv:"ip"`
+	Endpoint *url.URL `csv:"endpoint"`
+	Load     float64  `csv:"load"`
+}
+
+func newHostConverters() *csvutils.Converters {
+	converters := csvutils.NewConverters()
+	csvutils.RegisterConverter(converters,
+		func(s string) (net.IP, error) { return net.ParseIP(s), nil },
+		func(ip net.IP) (string, error) { return ip.String(), nil },
+	)
+	csvutils.RegisterConverter(converters,
+		url.Parse,
+		func(u *url.URL) (string, error) { return u.String(), nil },
+	)
+	return converters
+}
+
+func TestConverters_RoundTrip(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/health")
+	hosts := []Host{{Name: "web-1", IP: net.ParseIP("10.0.0.1"), Endpoint: endpoint, Load: 0.5}}
+	converters := newHostConverters()
+
+	// Write the records
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Host](&buf, csvutils.WithConverters(converters))
+	if err := encoder.EncodeAll(hosts); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "name,ip,endpoint,load\nweb-1,10.0.0.1,https://example.com/health,0.5\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Read them back
+	records, err := csvutils.ReadAll[Host](&buf, csvutils.WithConverters(converters))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if !reflect.DeepEqual(records, hosts) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, hosts)
+	}
+}
+
+func TestConverters_OverrideFloatFormatting(t *testing.T) {
+	converters := newHostConverters()
+	csvutils.RegisterConverter(converters, nil, func(f float64) (string, error) {
+		return strconv.FormatFloat(f, 'f', 3, 64), nil
+	})
+
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Host](&buf, csvutils.WithConverters(converters))
+	if err := encoder.Encode(Host{Name: "web-1", IP: net.ParseIP("10.0.0.1"), Load: 0.5}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "web-1,10.0.0.1,,0.500\n") {
+		t.Errorf("expected the overridden float format, got: %q", buf.String())
+	}
+}
+
+// Percent is a third-party style type only known to the global registry.
+type Percent float64
+
+func TestConverters_Global(t *testing.T) {
+	csvutils.RegisterConverter(csvutils.GlobalConverters,
+		func(s string) (Percent, error) {
+			f, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+			return Percent(f / 100), err
+		},
+		nil,
+	)
+
+	type Metric struct {
+		Name  string  `csv:"name"`
+		Ratio Percent `csv:"ratio"`
+	}
+	records, err := csvutils.ReadAll[Metric](strings.NewReader("name,ratio\ncpu,25%\n"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+
+	expected := []Metric{{Name: "cpu", Ratio: 0.25}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+}
+
+func TestConverters_FormatOnly(t *testing.T) {
+	type Point struct {
+		X int `csv:"x"`
+		Y int `csv:"y"`
+	}
+	type Shape struct {
+		Name   string `csv:"name"`
+		Origin Point  `csv:"origin"`
+	}
+	converters := csvutils.NewConverters()
+	csvutils.RegisterConverter(converters, nil, func(p Point) (string, error) {
+		return strconv.Itoa(p.X) + ":" + strconv.Itoa(p.Y), nil
+	})
+
+	// The writer formats the struct into one column
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Shape](&buf, csvutils.WithConverters(converters))
+	if err := encoder.EncodeAll([]Shape{{Name: "dot", Origin: Point{X: 1, Y: 2}}}); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if expected := "name,origin\ndot,1:2\n"; buf.String() != expected {
+		t.Errorf("Written CSV does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// The reader has no Parse function and binds the nested columns instead
+	records, err := csvutils.ReadAll[Shape](strings.NewReader("name,origin_x,origin_y\ndot,1,2\n"), csvutils.WithConverters(converters))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if expected := []Shape{{Name: "dot", Origin: Point{X: 1, Y: 2}}}; !reflect.DeepEqual(records, expected) {
+		t.Errorf("Records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+}
@@ -0,0 +1,85 @@
+package csvutils
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"reflect"
+)
+
+// Decoder reads CSV records from an io.Reader and decodes them into values of type T,
+// which must be a struct. The header is read and the field plan is built once, when the
+// Decoder is created, and reused for every record.
+type Decoder[T any] struct {
+	reader   *recordReader
+	elemType reflect.Type
+}
+
+// NewDecoder reads the header from r and returns a Decoder for the remaining records.
+func NewDecoder[T any](r io.Reader, options ...func(*csvOptions)) (*Decoder[T], error) {
+	elemType, err := recordElemType((*T)(nil))
+	if err != nil {
+		return nil, errors.New("decoder type must be a struct")
+	}
+	reader, err := newRecordReader(r, elemType, newCsvOptions(options))
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder[T]{reader: reader, elemType: elemType}, nil
+}
+
+// Decode reads the next record into v, overwriting its previous contents. It returns
+// io.EOF when there are no more records.
+func (d *Decoder[T]) Decode(v *T) error {
+	record, line, err := d.reader.read()
+	if err != nil {
+		return err
+	}
+	var zero T
+	*v = zero
+	return d.reader.populate(reflect.ValueOf(v).Elem(), record, line)
+}
+
+// UnboundColumns returns the column names of the fields that did not match any header
+// cell. Those fields are left at their zero or default values.
+func (d *Decoder[T]) UnboundColumns() []string {
+	return unboundColumns(d.reader.fieldInfo)
+}
+
+// ReadAll decodes every record from r.
+func ReadAll[T any](r io.Reader, options ...func(*csvOptions)) ([]T, error) {
+	var records []T
+	for record, err := range All[T](r, options...) {
+		if err != nil {
+			return records, err
+		}
+		records = append(records, *record)
+	}
+	return records, nil
+}
+
+// All returns an iterator over the records decoded from r. Iteration stops after the
+// first error, which is yielded with a nil record.
+func All[T any](r io.Reader, options ...func(*csvOptions)) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		decoder, err := NewDecoder[T](r, options...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for {
+			record := new(T)
+			err := decoder.Decode(record)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package csvutils_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+const decoderTestData = `Name,Age,Email
+Alice,30,alice@example.com
+Bob,35,bob@example.com
+`
+
+func TestDecoder(t *testing.T) {
+	decoder, err := csvutils.NewDecoder[TestStruct](strings.NewReader(decoderTestData))
+	if err != nil {
+		t.Fatalf("NewDecoder returned error: %v", err)
+	}
+
+	// Decode records one at a time until io.EOF
+	var records []TestStruct
+	for {
+		var record TestStruct
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode returned error: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	expected := []TestStruct{
+		{Name: "Alice", Age: 30, Email: "alice@example.com"},
+		{Name: "Bob", Age: 35, Email: "bob@example.com"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Decoded records do not match expected. Got: %v, Expected: %v", records, expected)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	records, err := csvutils.ReadAll[TestStruct](strings.NewReader(decoderTestData))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+
+	expected := []TestStruct{
+		{Name: "Alice", Age: 30, Email: "alice@example.com"},
+		{Name: "Bob", Age: 35, Email: "bob@example.com"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Decoded records do not match expected. Got: %v, Expected: %v", records, expected)
+	}
+}
+
+func TestAll_StopsOnError(t *testing.T) {
+	data := `Name,Age,Email
+Alice,30,alice@example.com
+Bob,abc,bob@example.com
+Carol,40,carol@example.com
+`
+
+	// Range over the records and collect the first error
+	var names []string
+	var iterErr error
+	for record, err := range csvutils.All[TestStruct](strings.NewReader(data)) {
+		if err != nil {
+			iterErr = err
+			continue
+		}
+		names = append(names, record.Name)
+	}
+
+	if iterErr == nil || !strings.Contains(iterErr.Error(), "line 3") {
+		t.Errorf("expected an error for line 3, got: %v", iterErr)
+	}
+	if !reflect.DeepEqual(names, []string{"Alice"}) {
+		t.Errorf("expected iteration to stop at the failing record, got: %v", names)
+	}
+}
+
+func TestNewDecoder_NonStruct(t *testing.T) {
+	if _, err := csvutils.NewDecoder[int](strings.NewReader(decoderTestData)); err == nil {
+		t.Errorf("expected an error for a non-struct decoder type")
+	}
+}
@@ -0,0 +1,72 @@
+package csvutils
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Dialect describes the CSV format used by both the reader and the writer. The zero
+// value is the encoding/csv default: comma separated, LF line endings and strict quoting.
+type Dialect struct {
+	// Comma is the field delimiter. Zero means ','.
+	Comma rune
+	// Comment, if not zero, marks lines starting with it as comments to skip on read.
+	Comment rune
+	// LazyQuotes allows quotes in unquoted fields and non-doubled quotes in quoted fields.
+	LazyQuotes bool
+	// TrimLeadingSpace ignores leading white space in a field on read.
+	TrimLeadingSpace bool
+	// FieldsPerRecord is the expected number of fields per record, see csv.Reader.
+	FieldsPerRecord int
+	// UseCRLF writes \r\n as the line terminator.
+	UseCRLF bool
+	// BOM writes a UTF-8 byte order mark before the header. A leading byte order mark
+	// is always skipped on read.
+	BOM bool
+	// ReuseRecord lets the reader reuse the backing array of the previous record. Records
+	// dispatched to the worker pool by ReadCSV are still copied, so this only saves
+	// allocations for the Decoder.
+	ReuseRecord bool
+}
+
+var (
+	// DialectRFC4180 is the strict format described by RFC 4180.
+	DialectRFC4180 = Dialect{Comma: ',', UseCRLF: true}
+	// DialectExcel matches the files produced by Microsoft Excel.
+	DialectExcel = Dialect{Comma: ',', UseCRLF: true, LazyQuotes: true}
+	// DialectTSV is tab separated with lenient quoting.
+	DialectTSV = Dialect{Comma: '\t', LazyQuotes: true}
+	// DialectUnix is comma separated with LF line endings.
+	DialectUnix = Dialect{Comma: ','}
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// WithDialect sets the CSV dialect used to read or write records.
+func WithDialect(dialect Dialect) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.dialect = dialect
+	}
+}
+
+func (d Dialect) newReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	if d.Comma != 0 {
+		reader.Comma = d.Comma
+	}
+	reader.Comment = d.Comment
+	reader.LazyQuotes = d.LazyQuotes
+	reader.TrimLeadingSpace = d.TrimLeadingSpace
+	reader.FieldsPerRecord = d.FieldsPerRecord
+	reader.ReuseRecord = d.ReuseRecord
+	return reader
+}
+
+func (d Dialect) newWriter(w io.Writer) *csv.Writer {
+	writer := csv.NewWriter(w)
+	if d.Comma != 0 {
+		writer.Comma = d.Comma
+	}
+	writer.UseCRLF = d.UseCRLF
+	return writer
+}
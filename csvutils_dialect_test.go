@@ -0,0 +1,106 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+func TestReadCSVFromReader_Dialect(t *testing.T) {
+	// Semicolon separated data with comment lines and padded fields
+	data := strings.NewReader(`# exported by ERP
+Name; Age; Email
+Alice; 30; alice@example.com
+# end of section
+Bob; 35; bob@example.com
+`)
+
+	var records []TestStruct
+	handler := func(record interface{}) error {
+		records = append(records, *record.(*TestStruct))
+		return nil
+	}
+
+	dialect := csvutils.Dialect{Comma: ';', Comment: '#', TrimLeadingSpace: true}
+	err := csvutils.ReadCSVFromReader(data, &TestStruct{}, csvutils.WithHandler(handler), csvutils.WithDialect(dialect))
+	if err != nil {
+		t.Fatalf("ReadCSVFromReader returned error: %v", err)
+	}
+
+	expected := []TestStruct{
+		{Name: "Alice", Age: 30, Email: "alice@example.com"},
+		{Name: "Bob", Age: 35, Email: "bob@example.com"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %v, Expected: %v", records, expected)
+	}
+}
+
+func TestReadAll_ReuseRecord(t *testing.T) {
+	dialect := csvutils.DialectTSV
+	dialect.ReuseRecord = true
+
+	data := "Name\tAge\tEmail\nAlice\t30\talice@example.com\nBob\t35\tbob@example.com\n"
+	records, err := csvutils.ReadAll[TestStruct](strings.NewReader(data), csvutils.WithDialect(dialect))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+
+	expected := []TestStruct{
+		{Name: "Alice", Age: 30, Email: "alice@example.com"},
+		{Name: "Bob", Age: 35, Email: "bob@example.com"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %v, Expected: %v", records, expected)
+	}
+}
+
+func TestEncoder_Dialect(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[TestStruct](&buf, csvutils.WithDialect(csvutils.DialectExcel))
+	if err := encoder.Encode(TestStruct{Name: "Alice", Age: 30, Email: "alice@example.com"}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "Name,Age,Email\r\nAlice,30,alice@example.com\r\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Tab separated output
+	buf.Reset()
+	encoder = csvutils.NewEncoder[TestStruct](&buf, csvutils.WithDialect(csvutils.DialectTSV))
+	if err := encoder.Encode(TestStruct{Name: "Bob", Age: 35}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected = "Name\tAge\tEmail\nBob\t35\t\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+}
+
+func TestEncoder_BOM(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[TestStruct](&buf, csvutils.WithDialect(csvutils.Dialect{BOM: true}))
+	if err := encoder.Encode(TestStruct{Name: "Alice", Age: 30}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "\xEF\xBB\xBFName,Age,Email\nAlice,30,\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+}
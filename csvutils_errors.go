@@ -0,0 +1,287 @@
+package csvutils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrorPolicy decides what happens to a record that fails to decode or whose handler
+// returns an error.
+type ErrorPolicy int
+
+const (
+	// FailFast stops reading at the first failed record. It is the default.
+	FailFast ErrorPolicy = iota
+	// SkipAndCollect skips failed records and returns their errors, joined in line
+	// order, once the whole file has been read.
+	SkipAndCollect
+	// SkipAndWrite skips failed records and writes them verbatim to the writer set with
+	// WithRejectsWriter, after the error_line, error_column and error_message columns.
+	// Rejected records are not returned as errors.
+	SkipAndWrite
+)
+
+// ErrTooManyErrors is returned when more records failed than allowed by WithMaxErrors.
+var ErrTooManyErrors = errors.New("too many errors")
+
+// WithErrorPolicy sets how failed records are handled.
+func WithErrorPolicy(policy ErrorPolicy) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.errorPolicy = policy
+	}
+}
+
+// WithRejectsWriter sets where SkipAndWrite writes failed records. The rejects are
+// written in the dialect of the input, with its header, in line order once the read
+// has finished.
+func WithRejectsWriter(w io.Writer) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.rejects = w
+	}
+}
+
+// WithMaxErrors aborts reading with ErrTooManyErrors once more than n records failed
+// under a skipping error policy. Zero, the default, allows any number of errors.
+func WithMaxErrors(n int) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.maxErrors = n
+	}
+}
+
+// ParseError describes a record that could not be decoded, either because the CSV is
+// malformed or because a cell could not be converted to its field. Use errors.As to
+// retrieve it; for malformed CSV, Err is the *csv.ParseError of the underlying reader.
+type ParseError struct {
+	// Line is the line the record starts on.
+	Line int
+	// Column is the 1-based position of the field in the record or, for malformed CSV,
+	// the column reported by encoding/csv.
+	Column int
+	// Header is the name of the column the field is bound to.
+	Header string
+	// FieldName is the name of the struct field.
+	FieldName string
+	// RawValue is the text that failed to convert.
+	RawValue string
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	if e.FieldName == "" {
+		return fmt.Sprintf("failed to read record: %v", e.Err)
+	}
+	return fmt.Sprintf("line %d, column %q: failed to set field value for field %s: %v", e.Line, e.Header, e.FieldName, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// recordErrors collects the errors reported by concurrent record workers and applies
+// the error policy to them.
+type recordErrors struct {
+	mu        sync.Mutex
+	stop      atomic.Bool
+	entries   []lineError
+	policy    ErrorPolicy
+	maxErrors int
+	tooMany   bool
+
+	rejects    io.Writer
+	rejectsCSV *csv.Writer
+	scratch    bytes.Buffer
+	comma      string
+	newline    string
+	header     []string
+}
+
+type lineError struct {
+	line int
+	raw  []byte
+	err  error
+	// reject reports whether the record goes to the rejects writer.
+	reject bool
+}
+
+func newRecordErrors(opts *csvOptions, header []string) (*recordErrors, error) {
+	e := &recordErrors{policy: opts.errorPolicy, maxErrors: opts.maxErrors, header: header}
+	if opts.errorPolicy == SkipAndWrite {
+		if opts.rejects == nil {
+			return nil, errors.New("error policy SkipAndWrite requires WithRejectsWriter")
+		}
+		e.rejects = opts.rejects
+		e.rejectsCSV = opts.dialect.newWriter(&e.scratch)
+		e.comma, e.newline = string(e.rejectsCSV.Comma), "\n"
+		if opts.dialect.UseCRLF {
+			e.newline = "\r\n"
+		}
+	}
+	return e, nil
+}
+
+// capturesRaw reports whether the reader must keep the raw bytes of every record for
+// the rejects.
+func (opts *csvOptions) capturesRaw() bool {
+	return opts.errorPolicy == SkipAndWrite && opts.rejects != nil
+}
+
+// add records the failure of the record on the given line and reports whether reading
+// must stop. raw is the record as it appears in the input.
+func (e *recordErrors) add(line int, raw []byte, err error) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.entries = append(e.entries, lineError{line: line, raw: raw, err: err, reject: e.rejects != nil})
+	switch {
+	case e.policy == FailFast:
+		e.stop.Store(true)
+	case e.maxErrors > 0 && len(e.entries) > e.maxErrors:
+		e.tooMany = true
+		e.stop.Store(true)
+	}
+	return e.stop.Load()
+}
+
+// fail records an error that ends the read whatever the policy.
+func (e *recordErrors) fail(line int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.entries = append(e.entries, lineError{line: line, err: err})
+	e.policy = FailFast
+	e.stop.Store(true)
+}
+
+// writeRejects writes the rejected records, which must be sorted by line, below the
+// header of the input.
+func (e *recordErrors) writeRejects() error {
+	headerWritten := false
+	for _, entry := range e.entries {
+		if !entry.reject {
+			continue
+		}
+		if !headerWritten && len(e.header) > 0 {
+			headerWritten = true
+			if err := e.writeRow(slices.Concat([]string{"error_line", "error_column", "error_message"}, e.header), nil); err != nil {
+				return fmt.Errorf("failed to write rejects header: %w", err)
+			}
+		}
+		if err := e.writeReject(entry.line, entry.raw, entry.err); err != nil {
+			return fmt.Errorf("failed to write rejected record: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeReject writes the error columns followed by the raw record. Leading error
+// columns stay under their header whatever the number of fields of the record, which
+// may be malformed.
+func (e *recordErrors) writeReject(line int, raw []byte, err error) error {
+	var column string
+	var parseErr *ParseError
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &parseErr):
+		column = parseErr.Header
+	case errors.As(err, &validationErr):
+		column = validationErr.Header
+	}
+	return e.writeRow([]string{strconv.Itoa(line), column, err.Error()}, raw)
+}
+
+// writeRow encodes cells in the dialect of the input and appends raw, if any, as the
+// remaining fields of the row.
+func (e *recordErrors) writeRow(cells []string, raw []byte) error {
+	e.scratch.Reset()
+	if err := e.rejectsCSV.Write(cells); err != nil {
+		return err
+	}
+	e.rejectsCSV.Flush()
+	if err := e.rejectsCSV.Error(); err != nil {
+		return err
+	}
+	if raw != nil {
+		e.scratch.Truncate(e.scratch.Len() - len(e.newline))
+		e.scratch.WriteString(e.comma)
+		e.scratch.Write(bytes.TrimRight(raw, "\r\n"))
+		e.scratch.WriteString(e.newline)
+	}
+	_, err := e.rejects.Write(e.scratch.Bytes())
+	return err
+}
+
+// rawRecorder keeps the bytes read through it until they are taken, so the input of
+// every record can be recovered from the offsets reported by csv.Reader.
+type rawRecorder struct {
+	r      io.Reader
+	buf    []byte
+	offset int64 // input offset of buf[0]
+}
+
+func (rr *rawRecorder) Read(p []byte) (int, error) {
+	n, err := rr.r.Read(p)
+	rr.buf = append(rr.buf, p[:n]...)
+	return n, err
+}
+
+// take returns a copy of the bytes up to the input offset end and discards them.
+func (rr *rawRecorder) take(end int64) []byte {
+	n := int(end - rr.offset)
+	taken := slices.Clone(rr.buf[:n])
+	rr.buf = append(rr.buf[:0], rr.buf[n:]...)
+	rr.offset = end
+	return taken
+}
+
+// rawRecord returns the input of the record before end, without the blank and comment
+// lines csv.Reader skipped in front of it.
+func (rr *rawRecorder) rawRecord(end int64, comment rune) []byte {
+	raw := rr.take(end)
+	for len(raw) > 0 {
+		line, rest, _ := bytes.Cut(raw, []byte("\n"))
+		blank := len(bytes.TrimRight(line, "\r")) == 0
+		if !blank && (comment == 0 || !bytes.HasPrefix(line, []byte(string(comment)))) {
+			break
+		}
+		raw = rest
+	}
+	return raw
+}
+
+// stopped reports whether reading must stop.
+func (e *recordErrors) stopped() bool {
+	return e.stop.Load()
+}
+
+// err returns the error of the read according to the policy: the collected errors
+// joined in line order, or nil if none were reported.
+func (e *recordErrors) err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// Workers fail records out of order, so entries are sorted by line first.
+	sort.SliceStable(e.entries, func(i, j int) bool {
+		return e.entries[i].line < e.entries[j].line
+	})
+	if e.rejects != nil {
+		if err := e.writeRejects(); err != nil {
+			return err
+		}
+	}
+	if len(e.entries) == 0 || (e.policy == SkipAndWrite && !e.tooMany) {
+		return nil
+	}
+	errs := make([]error, 0, len(e.entries)+1)
+	if e.tooMany {
+		errs = append(errs, fmt.Errorf("%w: more than %d records failed", ErrTooManyErrors, e.maxErrors))
+	}
+	for _, entry := range e.entries {
+		errs = append(errs, entry.err)
+	}
+	return errors.Join(errs...)
+}
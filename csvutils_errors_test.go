@@ -0,0 +1,214 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+const rowErrorsData = "Name,Age,Email\n" +
+	"Ann,30,ann@example.com\n" +
+	"Bob,abc,bob@example.com\n" +
+	"Cid,40\n" +
+	"Dee,50,dee@example.com\n"
+
+func TestErrorPolicy_SkipAndCollect(t *testing.T) {
+	var names []string
+	handler := csvutils.WithHandler(func(record interface{}) error {
+		names = append(names, record.(*TestStruct).Name)
+		return nil
+	})
+
+	// Failed records are skipped and their errors returned at the end
+	err := csvutils.ReadCSVFromReader(strings.NewReader(rowErrorsData), &TestStruct{}, handler, csvutils.WithErrorPolicy(csvutils.SkipAndCollect))
+	if err == nil {
+		t.Fatal("Expected collected errors, got nil")
+	}
+	for _, part := range []string{`line 3, column "Age"`, "record on line 4: wrong number of fields"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("Expected error to contain %q, got: %v", part, err)
+		}
+	}
+	if expected := []string{"Ann", "Dee"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Handled records do not match expected. Got: %v, Expected: %v", names, expected)
+	}
+}
+
+func TestErrorPolicy_SkipAndWrite(t *testing.T) {
+	var names []string
+	handler := csvutils.WithHandler(func(record interface{}) error {
+		if record.(*TestStruct).Name == "Dee" {
+			return errors.New("duplicate customer")
+		}
+		names = append(names, record.(*TestStruct).Name)
+		return nil
+	})
+
+	// Failed records go to the rejects writer and are not returned as errors
+	var rejects bytes.Buffer
+	err := csvutils.ReadCSVFromReader(strings.NewReader(rowErrorsData), &TestStruct{}, handler,
+		csvutils.WithErrorPolicy(csvutils.SkipAndWrite), csvutils.WithRejectsWriter(&rejects))
+	if err != nil {
+		t.Fatalf("ReadCSVFromReader returned error: %v", err)
+	}
+	if expected := []string{"Ann"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Handled records do not match expected. Got: %v, Expected: %v", names, expected)
+	}
+
+	// The rejects keep the original cells, after the error columns
+	reader := csv.NewReader(&rejects)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse rejects: %v", err)
+	}
+	expected := [][]string{
+		{"error_line", "error_column", "error_message", "Name", "Age", "Email"},
+		{"3", "Age", "Bob", "abc", "bob@example.com"},
+		{"4", "", "Cid", "40"},
+		{"5", "", "Dee", "50", "dee@example.com"},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rejects rows, got %d: %v", len(expected), len(rows), rows)
+	}
+	for i, row := range rows {
+		if i == 0 {
+			if !reflect.DeepEqual(row, expected[0]) {
+				t.Errorf("Rejects header does not match expected. Got: %v, Expected: %v", row, expected[0])
+			}
+			continue
+		}
+		if got := slices.Concat(row[:2], row[3:]); !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("Rejects row %d does not match expected. Got: %v, Expected: %v", i, got, expected[i])
+		}
+	}
+	if message := rows[3][2]; !strings.Contains(message, "duplicate customer") {
+		t.Errorf("Expected handler error message, got: %q", message)
+	}
+}
+
+func TestErrorPolicy_MaxErrors(t *testing.T) {
+	// The read aborts once more records failed than allowed
+	var rejects bytes.Buffer
+	err := csvutils.ReadCSVFromReader(strings.NewReader(rowErrorsData), &TestStruct{},
+		csvutils.WithErrorPolicy(csvutils.SkipAndWrite), csvutils.WithRejectsWriter(&rejects), csvutils.WithMaxErrors(1))
+	if !errors.Is(err, csvutils.ErrTooManyErrors) {
+		t.Errorf("Expected ErrTooManyErrors, got: %v", err)
+	}
+
+	// Within the threshold the read succeeds
+	err = csvutils.ReadCSVFromReader(strings.NewReader(rowErrorsData), &TestStruct{},
+		csvutils.WithErrorPolicy(csvutils.SkipAndWrite), csvutils.WithRejectsWriter(&rejects), csvutils.WithMaxErrors(2))
+	if err != nil {
+		t.Errorf("ReadCSVFromReader returned error: %v", err)
+	}
+}
+
+func TestErrorPolicy_SkipAndWriteRequiresWriter(t *testing.T) {
+	err := csvutils.ReadCSVFromReader(strings.NewReader(rowErrorsData), &TestStruct{}, csvutils.WithErrorPolicy(csvutils.SkipAndWrite))
+	if err == nil || !strings.Contains(err.Error(), "requires WithRejectsWriter") {
+		t.Errorf("Expected missing rejects writer error, got: %v", err)
+	}
+}
+
+func TestErrorPolicy_OrderedOutput(t *testing.T) {
+	var names []string
+	handler := csvutils.WithHandler(func(record interface{}) error {
+		names = append(names, record.(*TestStruct).Name)
+		return nil
+	})
+
+	// Skipped records keep the remaining records in file order
+	err := csvutils.ReadCSVFromReader(strings.NewReader(rowErrorsData), &TestStruct{}, handler,
+		csvutils.WithConcurrency(4), csvutils.WithOrderedOutput(0), csvutils.WithErrorPolicy(csvutils.SkipAndCollect))
+	if err == nil {
+		t.Fatal("Expected collected errors, got nil")
+	}
+	if expected := []string{"Ann", "Dee"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Handled records do not match expected. Got: %v, Expected: %v", names, expected)
+	}
+}
+
+func TestParseError_As(t *testing.T) {
+	// Conversion errors describe the cell that failed
+	_, err := csvutils.ReadAll[TestStruct](strings.NewReader("Name,Age,Email\nAnn,30,ann@example.com\nBob,abc,bob@example.com\n"))
+	var parseErr *csvutils.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got: %v", err)
+	}
+	expected := csvutils.ParseError{Line: 3, Column: 2, Header: "Age", FieldName: "Age", RawValue: "abc"}
+	got := *parseErr
+	got.Err = nil
+	if got != expected {
+		t.Errorf("ParseError does not match expected. Got: %+v, Expected: %+v", got, expected)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected the conversion error to be wrapped, got: %v", err)
+	}
+
+	// Malformed CSV keeps the position reported by encoding/csv
+	_, err = csvutils.ReadAll[TestStruct](strings.NewReader("Name,Age,Email\nAnn,30,ann@example.com\nBob,\"4\"0,bob@example.com\n"))
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got: %v", err)
+	}
+	if parseErr.Line != 3 || parseErr.Column != 7 || parseErr.FieldName != "" {
+		t.Errorf("ParseError does not match expected position: %+v", parseErr)
+	}
+	var csvErr *csv.ParseError
+	if !errors.As(err, &csvErr) || !errors.Is(err, csv.ErrQuote) {
+		t.Errorf("Expected the csv.ParseError to be wrapped, got: %v", err)
+	}
+}
+
+func TestErrorPolicy_SkipAndWriteMalformed(t *testing.T) {
+	data := "Name,Age,Email\n" +
+		"Ann,30,ann@example.com\n" +
+		"Bob,\"4\"0,bob@example.com\n" +
+		"\n" +
+		"Cid,40,cid@example.com,extra\n" +
+		"Dee,50,dee@example.com\n"
+
+	// Malformed records are written exactly as they appear in the input
+	var rejects bytes.Buffer
+	err := csvutils.ReadCSVFromReader(strings.NewReader(data), &TestStruct{},
+		csvutils.WithErrorPolicy(csvutils.SkipAndWrite), csvutils.WithRejectsWriter(&rejects))
+	if err != nil {
+		t.Fatalf("ReadCSVFromReader returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(rejects.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 rejected records, got: %q", rejects.String())
+	}
+	expected := []struct{ prefix, suffix string }{
+		{"3,,", `,Bob,"4"0,bob@example.com`},
+		{"5,,", ",Cid,40,cid@example.com,extra"},
+	}
+	for i, test := range expected {
+		line := lines[i+1]
+		if !strings.HasPrefix(line, test.prefix) || !strings.HasSuffix(line, test.suffix) {
+			t.Errorf("Rejected record %d does not match expected. Got: %q", i+1, line)
+		}
+	}
+	if !strings.Contains(lines[1], "extraneous or missing \"\" in quoted-field") {
+		t.Errorf("Expected the csv error message, got: %q", lines[1])
+	}
+}
+
+func TestParseError_MultiLineRecord(t *testing.T) {
+	// The line is where the record starts, not where the error occurred
+	_, err := csvutils.ReadAll[TestStruct](strings.NewReader("Name,Age,Email\n\"Ann\nSmith\"x,30,ann@example.com\n"))
+	var parseErr *csvutils.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got: %v", err)
+	}
+	if parseErr.Line != 2 {
+		t.Errorf("Expected line 2, got %d: %v", parseErr.Line, err)
+	}
+}
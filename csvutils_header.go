@@ -0,0 +1,223 @@
+package csvutils
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// HeaderMatcher maps header cells and column names to match keys. A field binds to the
+// header cell whose key equals the key of its column name. Any func can be used to plug
+// in a custom strategy.
+type HeaderMatcher func(name string) string
+
+// MatchExact binds columns whose header is exactly the column name. It is the default.
+func MatchExact(name string) string {
+	return name
+}
+
+// MatchCaseInsensitive binds columns whose header equals the column name ignoring case.
+func MatchCaseInsensitive(name string) string {
+	return strings.ToLower(name)
+}
+
+// MatchNormalized binds columns ignoring case, whitespace and punctuation, so "E-mail ",
+// "e_mail" and "EMail" all match the column email.
+func MatchNormalized(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// MatchSnakeCamel binds columns that spell the same words in snake_case, kebab-case,
+// camelCase or PascalCase, so "CreatedAt", "createdAt" and "created_at" all match.
+func MatchSnakeCamel(name string) string {
+	var words []string
+	var word []rune
+	runes := []rune(strings.TrimSpace(name))
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.' || unicode.IsSpace(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			// A word starts at an upper case letter after a lower case letter or digit,
+			// or at the last upper case letter of an acronym, e.g. HTTPServer.
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return strings.Join(words, "_")
+}
+
+// WithHeaderMatcher sets how header cells are matched to column names, e.g.
+// WithHeaderMatcher(MatchCaseInsensitive).
+func WithHeaderMatcher(matcher HeaderMatcher) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.headerMatcher = matcher
+	}
+}
+
+// unboundColumns returns the column names of the fields that did not bind to any header.
+func unboundColumns(infos []fieldInfo) []string {
+	var columns []string
+	walkLeaves(infos, func(info fieldInfo) {
+		if info.columnIndex < 0 {
+			columns = append(columns, info.columnName)
+		}
+	})
+	return columns
+}
+
+// WithNoHeader reads and writes files without a header row. Readers bind fields by
+// position with the `index:"3"` or `csv:",index=3"` tag, counted from 0, or by name
+// against a header supplied with WithHeader. Writers put those fields at their
+// position, whether or not a header is written.
+func WithNoHeader() func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.noHeader = true
+	}
+}
+
+// WithHeader uses header in place of the header row of the file, e.g. when it is wrong
+// or, together with WithNoHeader, missing. Writers write it instead of the header
+// derived from the record type and put every value under its column; fields missing
+// from the header are not written and a header cell without a field is an error.
+func WithHeader(header ...string) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.header = header
+	}
+}
+
+// WithSkipLines skips the first n lines, such as report titles and blank lines, before
+// the header or, with WithNoHeader, the data. Lines are counted as they appear in the
+// file, so a quoted cell spanning several lines is not treated as one record.
+func WithSkipLines(n int) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.skipLines = n
+	}
+}
+
+// WithHeaderFunc skips records until isHeader reports true and reads that record as the
+// header, e.g. to find the header below a preamble of variable length.
+func WithHeaderFunc(isHeader func(row []string) bool) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.headerFunc = isHeader
+	}
+}
+
+// WithHeaderRows reads a header that spans n rows, such as a group row above a column
+// row. The cells of each column are joined with the nesting separator, so a group
+// "address" above "street" names the column address_street. An empty cell in any row but
+// the last repeats the group to its left, like a merged spreadsheet cell. CSV does not
+// record where a merged cell ends, so every column right of a group belongs to it until
+// the next group: place ungrouped columns before the first group, or name the columns
+// with WithHeader instead.
+func WithHeaderRows(n int) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.headerRows = n
+	}
+}
+
+// skipPreamble discards the lines before the header, before any of them is parsed as
+// CSV, so the preamble may hold unbalanced quotes.
+func (opts *csvOptions) skipPreamble(reader *bufio.Reader) error {
+	for i := 0; i < opts.skipLines; i++ {
+		line, err := reader.ReadSlice('\n')
+		for err == bufio.ErrBufferFull {
+			line, err = reader.ReadSlice('\n')
+		}
+		if err == io.EOF && len(line) > 0 {
+			err = nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to skip line %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// readHeader reads the header rows and combines them into a single row.
+func (opts *csvOptions) readHeader(reader *csv.Reader) ([]string, error) {
+	first, err := opts.findHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	rows := [][]string{slices.Clone(first)}
+	for len(rows) < opts.headerRows {
+		row, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, slices.Clone(row))
+	}
+	if len(rows) == 1 {
+		return rows[0], nil
+	}
+	return combineHeaderRows(rows, opts.separator), nil
+}
+
+func (opts *csvOptions) findHeader(reader *csv.Reader) ([]string, error) {
+	if opts.headerFunc == nil {
+		return reader.Read()
+	}
+	reader.FieldsPerRecord = -1
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		if opts.headerFunc(row) {
+			reader.FieldsPerRecord = opts.dialect.FieldsPerRecord
+			if reader.FieldsPerRecord == 0 {
+				reader.FieldsPerRecord = len(row)
+			}
+			return row, nil
+		}
+	}
+}
+
+func combineHeaderRows(rows [][]string, separator string) []string {
+	last := rows[len(rows)-1]
+	header := make([]string, len(last))
+	for column := range last {
+		var parts []string
+		for i, row := range rows {
+			var cell string
+			if column < len(row) {
+				cell = strings.TrimSpace(row[column])
+			}
+			if i < len(rows)-1 {
+				// Repeat the group of a merged cell to its left.
+				for left := column - 1; cell == "" && left >= 0; left-- {
+					if left < len(row) {
+						cell = strings.TrimSpace(row[left])
+					}
+				}
+			}
+			if cell != "" {
+				parts = append(parts, cell)
+			}
+		}
+		header[column] = strings.Join(parts, separator)
+	}
+	return header
+}
@@ -0,0 +1,324 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+func TestHeaderMatchers(t *testing.T) {
+	tests := []struct {
+		matcher  csvutils.HeaderMatcher
+		input    string
+		expected string
+	}{
+		{csvutils.MatchExact, "Created At", "Created At"},
+		{csvutils.MatchCaseInsensitive, "Created At", "created at"},
+		{csvutils.MatchNormalized, " E-mail_Address ", "emailaddress"},
+		{csvutils.MatchSnakeCamel, "CreatedAt", "created_at"},
+		{csvutils.MatchSnakeCamel, "created-at", "created_at"},
+		{csvutils.MatchSnakeCamel, "HTTPServer2Name", "http_server2_name"},
+		{csvutils.MatchSnakeCamel, " user ID ", "user_id"},
+	}
+	for _, test := range tests {
+		if actual := test.matcher(test.input); actual != test.expected {
+			t.Errorf("Matcher key for %q = %q, expected %q", test.input, actual, test.expected)
+		}
+	}
+}
+
+func TestWithHeaderMatcher(t *testing.T) {
+	type Account struct {
+		Email     string `csv:"email"`
+		CreatedBy string
+		Plan      string `csv:"plan"`
+	}
+	data := "E-Mail ,created_by\nann@example.com,bob\n"
+
+	tests := []struct {
+		name     string
+		matcher  csvutils.HeaderMatcher
+		expected Account
+		unbound  []string
+	}{
+		{"exact", csvutils.MatchExact, Account{}, []string{"email", "CreatedBy", "plan"}},
+		{"normalized", csvutils.MatchNormalized, Account{Email: "ann@example.com", CreatedBy: "bob"}, []string{"plan"}},
+		{"custom", func(name string) string { return strings.ToLower(strings.TrimSpace(strings.ReplaceAll(name, "-", ""))) }, Account{Email: "ann@example.com"}, []string{"CreatedBy", "plan"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Decode with the matcher and check which fields bound
+			decoder, err := csvutils.NewDecoder[Account](strings.NewReader(data), csvutils.WithHeaderMatcher(test.matcher))
+			if err != nil {
+				t.Fatalf("NewDecoder returned error: %v", err)
+			}
+			if unbound := decoder.UnboundColumns(); !reflect.DeepEqual(unbound, test.unbound) {
+				t.Errorf("Unbound columns do not match expected. Got: %v, Expected: %v", unbound, test.unbound)
+			}
+			var record Account
+			if err := decoder.Decode(&record); err != nil {
+				t.Fatalf("Decode returned error: %v", err)
+			}
+			if record != test.expected {
+				t.Errorf("Parsed record does not match expected. Got: %+v, Expected: %+v", record, test.expected)
+			}
+		})
+	}
+}
+
+type Extract struct {
+	Account string  `index:"0"`
+	Amount  float64 `csv:",index=2"`
+	Branch  string  `csv:"branch"`
+}
+
+func TestWithNoHeader(t *testing.T) {
+	data := "A-1,ignored,12.5,north\nA-2,ignored,7,south\n"
+
+	// Fields bind by position, the first row is data
+	records, err := csvutils.ReadAll[Extract](strings.NewReader(data), csvutils.WithNoHeader())
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expected := []Extract{{Account: "A-1", Amount: 12.5}, {Account: "A-2", Amount: 7}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+
+	// An explicit header binds the remaining fields by name
+	records, err = csvutils.ReadAll[Extract](strings.NewReader(data), csvutils.WithNoHeader(), csvutils.WithHeader("account", "code", "amount", "branch"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expected[0].Branch, expected[1].Branch = "north", "south"
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+
+	// Headerless output has no header row
+	var buf strings.Builder
+	encoder := csvutils.NewEncoder[TestStruct](&buf, csvutils.WithNoHeader())
+	if err := encoder.Encode(TestStruct{Name: "Ann", Age: 30, Email: "ann@example.com"}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if buf.String() != "Ann,30,ann@example.com\n" {
+		t.Errorf("Encoded data does not match expected. Got: %q", buf.String())
+	}
+}
+
+func TestWithHeader_ReplacesFileHeader(t *testing.T) {
+	data := "col1,col2,col3\nAnn,30,ann@example.com\n"
+
+	// The wrong header row is read and replaced
+	records, err := csvutils.ReadAll[TestStruct](strings.NewReader(data), csvutils.WithHeader("Name", "Age", "Email"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expected := []TestStruct{{Name: "Ann", Age: 30, Email: "ann@example.com"}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+}
+
+func TestIndexTag_Invalid(t *testing.T) {
+	type Invalid struct {
+		Value string `index:"first"`
+	}
+	_, err := csvutils.ReadAll[Invalid](strings.NewReader("a\n"), csvutils.WithNoHeader())
+	if err == nil || !strings.Contains(err.Error(), `invalid index tag "first"`) {
+		t.Errorf("Expected invalid index tag error, got: %v", err)
+	}
+}
+
+func TestPreambleAndMultiRowHeader(t *testing.T) {
+	type Site struct {
+		Name    string   `csv:"name"`
+		Address Location `csv:"address"`
+	}
+	expected := []Site{{Name: "HQ", Address: Location{Street: "1 Main St", City: "Springfield"}}}
+
+	tests := []struct {
+		name string
+		data string
+		read func(data string) ([]Site, error)
+	}{
+		{
+			name: "skip lines",
+			data: "Quarterly report\n\nname,address_street,address_city\nHQ,1 Main St,Springfield\n",
+			read: func(data string) ([]Site, error) {
+				return csvutils.ReadAll[Site](strings.NewReader(data), csvutils.WithSkipLines(2))
+			},
+		},
+		{
+			name: "header func",
+			data: "Quarterly report\n\nname,address_street,address_city\nHQ,1 Main St,Springfield\n",
+			read: func(data string) ([]Site, error) {
+				return csvutils.ReadAll[Site](strings.NewReader(data), csvutils.WithHeaderFunc(func(row []string) bool {
+					return len(row) > 0 && row[0] == "name"
+				}))
+			},
+		},
+		{
+			name: "header rows",
+			data: "Sites\n,Address,\nname,street,city\nHQ,1 Main St,Springfield\n",
+			read: func(data string) ([]Site, error) {
+				return csvutils.ReadAll[Site](strings.NewReader(data), csvutils.WithSkipLines(1), csvutils.WithHeaderRows(2),
+					csvutils.WithHeaderMatcher(csvutils.MatchCaseInsensitive))
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records, err := test.read(test.data)
+			if err != nil {
+				t.Fatalf("ReadAll returned error: %v", err)
+			}
+			if !reflect.DeepEqual(records, expected) {
+				t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+			}
+		})
+	}
+}
+
+func TestWithHeaderRows_TrailingColumn(t *testing.T) {
+	data := ",address,,\nid,street,city,notes\n1,1 Main St,Springfield,corner\n"
+
+	// A column right of a group belongs to the group
+	type Grouped struct {
+		ID      string   `csv:"id"`
+		Address Location `csv:"address"`
+		Notes   string   `csv:"address_notes"`
+	}
+	grouped, err := csvutils.ReadAll[Grouped](strings.NewReader(data), csvutils.WithHeaderRows(2))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if expected := []Grouped{{ID: "1", Address: Location{Street: "1 Main St", City: "Springfield"}, Notes: "corner"}}; !reflect.DeepEqual(grouped, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", grouped, expected)
+	}
+
+	// WithHeader names an ungrouped trailing column
+	type Branch struct {
+		ID      string   `csv:"id"`
+		Address Location `csv:"address"`
+		Notes   string   `csv:"notes"`
+	}
+	branches, err := csvutils.ReadAll[Branch](strings.NewReader(data), csvutils.WithHeaderRows(2),
+		csvutils.WithHeader("id", "address_street", "address_city", "notes"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if expected := []Branch{{ID: "1", Address: Location{Street: "1 Main St", City: "Springfield"}, Notes: "corner"}}; !reflect.DeepEqual(branches, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", branches, expected)
+	}
+}
+
+func TestWithSkipLines_LineNumbers(t *testing.T) {
+	// Skipped lines are counted as physical lines, even with an unbalanced quote
+	data := "Report \"Q1\n\nName,Age,Email\nAnn,30,ann@example.com\nBob,abc,bob@example.com\n"
+	_, err := csvutils.ReadAll[TestStruct](strings.NewReader(data), csvutils.WithSkipLines(2))
+	var parseErr *csvutils.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got: %v", err)
+	}
+	if parseErr.Line != 5 {
+		t.Errorf("Expected line 5, got %d: %v", parseErr.Line, err)
+	}
+
+	// More lines than the file holds
+	_, err = csvutils.ReadAll[TestStruct](strings.NewReader("Name,Age,Email\n"), csvutils.WithSkipLines(2))
+	if err == nil {
+		t.Error("Expected an error skipping past the end of the file, got nil")
+	}
+}
+
+func TestIndexTag_RoundTrip(t *testing.T) {
+	records := []Extract{{Account: "A-1", Amount: 1.5, Branch: "north"}}
+
+	// Positional fields are written at their index, gaps hold the null token
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Extract](&buf, csvutils.WithNoHeader())
+	if err := encoder.EncodeAll(records); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if buf.String() != "A-1,,1.5,north\n" {
+		t.Errorf("Encoded data does not match expected. Got: %q", buf.String())
+	}
+
+	// Reading them back binds the positional fields
+	parsed, err := csvutils.ReadAll[Extract](&buf, csvutils.WithNoHeader())
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expected := []Extract{{Account: "A-1", Amount: 1.5}}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", parsed, expected)
+	}
+
+	// With a header row every field round-trips
+	buf.Reset()
+	encoder = csvutils.NewEncoder[Extract](&buf)
+	if err := encoder.EncodeAll(records); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if buf.String() != "Account,,Amount,branch\nA-1,,1.5,north\n" {
+		t.Errorf("Encoded data does not match expected. Got: %q", buf.String())
+	}
+	parsed, err = csvutils.ReadAll[Extract](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", parsed, records)
+	}
+}
+
+func TestIndexTag_DuplicateOnWrite(t *testing.T) {
+	type Clash struct {
+		A string `index:"1"`
+		B string `csv:",index=1"`
+	}
+	err := csvutils.NewEncoder[Clash](&bytes.Buffer{}).Encode(Clash{})
+	if err == nil || !strings.Contains(err.Error(), "columns A and B both have index 1") {
+		t.Errorf("Expected duplicate index error, got: %v", err)
+	}
+	// The header supplied with WithHeader does not hide the clash
+	err = csvutils.NewEncoder[Clash](&bytes.Buffer{}, csvutils.WithHeader("x", "y")).Encode(Clash{})
+	if err == nil || !strings.Contains(err.Error(), "columns A and B both have index 1") {
+		t.Errorf("Expected duplicate index error, got: %v", err)
+	}
+}
+
+func TestWithHeader_Encoder(t *testing.T) {
+	// Values follow the supplied header, fields missing from it are left out
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[TestStruct](&buf, csvutils.WithHeader("email", "name"), csvutils.WithHeaderMatcher(csvutils.MatchCaseInsensitive))
+	if err := encoder.Encode(TestStruct{Name: "Ann", Age: 30, Email: "ann@example.com"}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if buf.String() != "email,name\nann@example.com,Ann\n" {
+		t.Errorf("Encoded data does not match expected. Got: %q", buf.String())
+	}
+
+	// A header cell without a field is an error
+	encoder = csvutils.NewEncoder[TestStruct](&buf, csvutils.WithHeader("Name", "Phone"))
+	if err := encoder.Encode(TestStruct{}); err == nil || !strings.Contains(err.Error(), `header column "Phone" does not match any field`) {
+		t.Errorf("Expected unmatched header error, got: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package csvutils
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// CSVUnmarshaler is implemented by types that decode themselves from a CSV cell and need
+// more context than the cell text, such as the column name or the rest of the row.
+type CSVUnmarshaler interface {
+	UnmarshalCSV(cell Cell) error
+}
+
+// CSVMarshaler is implemented by types that encode themselves into a CSV cell. It
+// receives the name of the column being written.
+type CSVMarshaler interface {
+	MarshalCSV(column string) (string, error)
+}
+
+// Cell is the CSV cell passed to a CSVUnmarshaler.
+type Cell struct {
+	// Column is the header name the field is bound to.
+	Column string
+	// Value is the cell text, after the `default` tag has been applied.
+	Value string
+	// Header is the header row of the file.
+	Header []string
+	// Row holds all cells of the record being decoded.
+	Row []string
+}
+
+var (
+	csvUnmarshalerType  = reflect.TypeOf((*CSVUnmarshaler)(nil)).Elem()
+	csvMarshalerType    = reflect.TypeOf((*CSVMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// implementsMarshaler reports whether t or *t converts itself with one of the supported
+// interfaces for the direction of opts: unmarshaling for readers, marshaling for writers.
+func implementsMarshaler(t reflect.Type, opts *csvOptions) bool {
+	ifaces := []reflect.Type{csvUnmarshalerType, textUnmarshalerType}
+	if opts.writing {
+		ifaces = []reflect.Type{csvMarshalerType, textMarshalerType}
+	}
+	ptr := reflect.PointerTo(t)
+	for _, iface := range ifaces {
+		if t.Implements(iface) || ptr.Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// asInterface returns v, or a pointer to it, as an interface{} if it implements iface.
+// Values that are not addressable are copied so pointer receivers can be used.
+func asInterface(v reflect.Value, iface reflect.Type) interface{} {
+	if v.Type().Implements(iface) {
+		return v.Interface()
+	}
+	if !reflect.PointerTo(v.Type()).Implements(iface) {
+		return nil
+	}
+	return addressOf(v).Interface()
+}
+
+// addressOf returns a pointer to v, or to a copy of v if it is not addressable.
+func addressOf(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr
+}
@@ -0,0 +1,172 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+// Money is stored in cents and rendered with two decimals.
+type Money struct {
+	Cents int64
+}
+
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%02d", m.Cents/100, m.Cents%100)), nil
+}
+
+func (m *Money) UnmarshalText(text []byte) error {
+	var units, cents int64
+	if _, err := fmt.Sscanf(string(text), "%d.%d", &units, &cents); err != nil {
+		return fmt.Errorf("invalid money %q: %w", text, err)
+	}
+	m.Cents = units*100 + cents
+	return nil
+}
+
+// Status is an enum encoded by name.
+type Status int
+
+const (
+	StatusActive Status = iota + 1
+	StatusClosed
+)
+
+func (s Status) MarshalText() ([]byte, error) {
+	switch s {
+	case StatusActive:
+		return []byte("active"), nil
+	case StatusClosed:
+		return []byte("closed"), nil
+	}
+	return nil, fmt.Errorf("unknown status %d", s)
+}
+
+func (s *Status) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "active":
+		*s = StatusActive
+	case "closed":
+		*s = StatusClosed
+	default:
+		return fmt.Errorf("unknown status %q", text)
+	}
+	return nil
+}
+
+// Amount reads its currency from the sibling "currency" column.
+type Amount struct {
+	Value    string
+	Currency string
+}
+
+func (a *Amount) UnmarshalCSV(cell csvutils.Cell) error {
+	a.Value = cell.Value
+	for i, header := range cell.Header {
+		if header == "currency" {
+			a.Currency = cell.Row[i]
+		}
+	}
+	return nil
+}
+
+func (a Amount) MarshalCSV(column string) (string, error) {
+	return a.Value, nil
+}
+
+type Account struct {
+	ID       string `csv:"id"`
+	Balance  Money  `csv:"balance"`
+	Limit    *Money `csv:"limit"`
+	Status   Status `csv:"status"`
+	Pending  Amount `csv:"pending"`
+	Currency string `csv:"currency"`
+}
+
+func TestMarshalerFields_RoundTrip(t *testing.T) {
+	accounts := []Account{
+		{
+			ID:       "acc-1",
+			Balance:  Money{Cents: 12345},
+			Limit:    &Money{Cents: 50000},
+			Status:   StatusActive,
+			Pending:  Amount{Value: "10.00", Currency: "EUR"},
+			Currency: "EUR",
+		},
+	}
+
+	// Write the records
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Account](&buf)
+	if err := encoder.EncodeAll(accounts); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "id,balance,limit,status,pending,currency\nacc-1,123.45,500.00,active,10.00,EUR\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Read them back
+	records, err := csvutils.ReadAll[Account](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if !reflect.DeepEqual(records, accounts) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, accounts)
+	}
+}
+
+func TestMarshalerFields_UnmarshalError(t *testing.T) {
+	data := "id,balance,limit,status,pending,currency\nacc-1,1.00,1.00,frozen,,EUR\n"
+
+	_, err := csvutils.ReadAll[Account](strings.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), `unknown status "frozen"`) {
+		t.Errorf("expected the unmarshal error to be reported, got: %v", err)
+	}
+}
+
+// Label can only be written, so readers flatten it into nested columns.
+type Label struct {
+	Text string `csv:"text"`
+}
+
+func (l Label) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(l.Text)), nil
+}
+
+func TestMarshalerFields_OneWay(t *testing.T) {
+	type Tagged struct {
+		ID    string `csv:"id"`
+		Label Label  `csv:"label"`
+	}
+
+	// The writer uses MarshalText for a single column
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Tagged](&buf)
+	if err := encoder.EncodeAll([]Tagged{{ID: "1", Label: Label{Text: "new"}}}); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if expected := "id,label\n1,NEW\n"; buf.String() != expected {
+		t.Errorf("Written CSV does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Without an unmarshaling path the reader binds the nested columns
+	records, err := csvutils.ReadAll[Tagged](strings.NewReader("id,label_text\n1,new\n"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if expected := []Tagged{{ID: "1", Label: Label{Text: "new"}}}; !reflect.DeepEqual(records, expected) {
+		t.Errorf("Records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+}
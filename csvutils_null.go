@@ -0,0 +1,84 @@
+package csvutils
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Nullable holds a value of type T that may be missing. Cells matching one of the null
+// tokens decode to a Nullable with Valid set to false.
+type Nullable[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NewNullable returns a valid Nullable holding v.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{V: v, Valid: true}
+}
+
+// Ptr returns a pointer to the value, or nil if it is not valid.
+func (n Nullable[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	return &n.V
+}
+
+// nullable is implemented by every Nullable instantiation.
+type nullable interface {
+	isNullable()
+}
+
+func (*Nullable[T]) isNullable() {}
+
+var nullableType = reflect.TypeOf((*nullable)(nil)).Elem()
+
+// WithNullTokens sets the cell values read as null; the first token is written for nil
+// pointers and invalid nullable values. Pointer fields stay nil and Nullable or sql.Null*
+// fields stay invalid when their cell is null. The default is the empty string only.
+func WithNullTokens(tokens ...string) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.nullTokens = tokens
+	}
+}
+
+func (opts *csvOptions) isNull(value string) bool {
+	return slices.Contains(opts.nullTokens, value)
+}
+
+func (opts *csvOptions) nullToken() string {
+	if len(opts.nullTokens) == 0 {
+		return ""
+	}
+	return opts.nullTokens[0]
+}
+
+// isNullableType reports whether t is a Nullable or one of the database/sql Null types,
+// all of which hold the value in their first field and a Valid flag in their second.
+func isNullableType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	if !reflect.PointerTo(t).Implements(nullableType) && t.PkgPath() != "database/sql" {
+		return false
+	}
+	valid := t.Field(1)
+	return valid.Name == "Valid" && valid.Type.Kind() == reflect.Bool
+}
+
+// getNullableSetter sets the value of a nullable type with the setter of its value type
+// and marks it valid. Null cells never reach the setter.
+func getNullableSetter(fieldType reflect.Type, field reflect.StructField, opts *csvOptions) (func(reflect.Value, string) error, error) {
+	setter, err := getFieldSetter(fieldType.Field(0).Type, field, opts)
+	if err != nil {
+		return nil, err
+	}
+	return func(v reflect.Value, s string) error {
+		if err := setter(v.Field(0), s); err != nil {
+			return err
+		}
+		v.Field(1).SetBool(true)
+		return nil
+	}, nil
+}
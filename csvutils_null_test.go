@@ -0,0 +1,94 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vd09/csvutils"
+)
+
+type Customer struct {
+	Name     string                     `csv:"name"`
+	Age      *int                       `csv:"age"`
+	Nickname *string                    `csv:"nickname"`
+	Email    sql.NullString             `csv:"email"`
+	Visits   sql.NullInt64              `csv:"visits"`
+	LastSeen sql.NullTime               `csv:"last_seen"`
+	Score    csvutils.Nullable[float64] `csv:"score"`
+}
+
+func TestNullableFields_Read(t *testing.T) {
+	data := `name,age,nickname,email,visits,last_seen,score
+Alice,30,,alice@example.com,NULL,2024-05-19T10:00:00Z,1.5
+Bob,NA,\N,,3,NULL,NA
+`
+
+	records, err := csvutils.ReadAll[Customer](strings.NewReader(data), csvutils.WithNullTokens("", "NULL", "NA", `\N`))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+
+	age := 30
+	expected := []Customer{
+		{
+			Name:     "Alice",
+			Age:      &age,
+			Email:    sql.NullString{String: "alice@example.com", Valid: true},
+			LastSeen: sql.NullTime{Time: time.Date(2024, 5, 19, 10, 0, 0, 0, time.UTC), Valid: true},
+			Score:    csvutils.NewNullable(1.5),
+		},
+		{
+			Name:   "Bob",
+			Visits: sql.NullInt64{Int64: 3, Valid: true},
+		},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+}
+
+func TestNullableFields_ZeroIsNotNull(t *testing.T) {
+	data := "name,age,nickname,visits\nAlice,0,\"\",0\n"
+
+	// Only NULL is a null token, so empty strings and zeros are real values
+	records, err := csvutils.ReadAll[Customer](strings.NewReader(data), csvutils.WithNullTokens("NULL"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+
+	record := records[0]
+	if record.Age == nil || *record.Age != 0 {
+		t.Errorf("expected age to be a pointer to 0, got: %v", record.Age)
+	}
+	if record.Nickname == nil || *record.Nickname != "" {
+		t.Errorf("expected nickname to be a pointer to an empty string, got: %v", record.Nickname)
+	}
+	if !record.Visits.Valid || record.Visits.Int64 != 0 {
+		t.Errorf("expected visits to be a valid 0, got: %+v", record.Visits)
+	}
+}
+
+func TestNullableFields_Write(t *testing.T) {
+	nickname := "ally"
+	customers := []Customer{
+		{Name: "Alice", Nickname: &nickname, Visits: sql.NullInt64{Int64: 3, Valid: true}, Score: csvutils.NewNullable(2.5)},
+	}
+
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Customer](&buf, csvutils.WithNullTokens(`\N`))
+	if err := encoder.EncodeAll(customers); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "name,age,nickname,email,visits,last_seen,score\nAlice,\\N,ally,\\N,3,\\N,2.5\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,134 @@
+package csvutils
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+const (
+	// EncodingBase64 encodes []byte fields with standard base64. It is the default.
+	EncodingBase64 = "base64"
+	// EncodingHex encodes []byte fields as lowercase hexadecimal.
+	EncodingHex = "hex"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// WithBasePrefixes accepts integers with a base prefix such as 0x, 0o or 0b, and
+// underscores between digits, as described by strconv.ParseInt with base 0.
+func WithBasePrefixes() func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.basePrefixes = true
+	}
+}
+
+// bytesEncoding returns the encoding of a []byte field from its `encoding` tag.
+func bytesEncoding(field reflect.StructField) string {
+	if encoding := field.Tag.Get("encoding"); encoding != "" {
+		return encoding
+	}
+	return EncodingBase64
+}
+
+func decodeBytes(s, encoding string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	switch encoding {
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(s)
+	case EncodingHex:
+		return hex.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("unknown bytes encoding %q", encoding)
+	}
+}
+
+func encodeBytes(b []byte, encoding string) (string, error) {
+	switch encoding {
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString(b), nil
+	case EncodingHex:
+		return hex.EncodeToString(b), nil
+	default:
+		return "", fmt.Errorf("unknown bytes encoding %q", encoding)
+	}
+}
+
+func getBigSetter(fieldType reflect.Type, opts *csvOptions) func(reflect.Value, string) error {
+	base := 10
+	if opts.basePrefixes {
+		base = 0
+	}
+	var parse func(s string) (interface{}, error)
+	switch fieldType {
+	case bigIntType:
+		parse = func(s string) (interface{}, error) {
+			i, ok := new(big.Int).SetString(s, base)
+			if !ok {
+				return nil, fmt.Errorf("error parsing big.Int value %s", s)
+			}
+			return i, nil
+		}
+	case bigFloatType:
+		parse = func(s string) (interface{}, error) {
+			// Keep enough precision for every decimal digit of the input.
+			prec := uint(len(s)) * 4
+			if prec < 64 {
+				prec = 64
+			}
+			f, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing big.Float value %s: %w", s, err)
+			}
+			return f, nil
+		}
+	case bigRatType:
+		parse = func(s string) (interface{}, error) {
+			r, ok := new(big.Rat).SetString(s)
+			if !ok {
+				return nil, fmt.Errorf("error parsing big.Rat value %s", s)
+			}
+			return r, nil
+		}
+	default:
+		return nil
+	}
+	return func(v reflect.Value, s string) error {
+		if s == "" {
+			v.Set(reflect.Zero(fieldType))
+			return nil
+		}
+		parsed, err := parse(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(parsed).Elem())
+		return nil
+	}
+}
+
+func getBigFormatter(fieldType reflect.Type) func(reflect.Value) (string, error) {
+	switch fieldType {
+	case bigIntType:
+		return func(v reflect.Value) (string, error) {
+			return addressOf(v).Interface().(*big.Int).String(), nil
+		}
+	case bigFloatType:
+		return func(v reflect.Value) (string, error) {
+			return addressOf(v).Interface().(*big.Float).Text('g', -1), nil
+		}
+	case bigRatType:
+		return func(v reflect.Value) (string, error) {
+			return addressOf(v).Interface().(*big.Rat).RatString(), nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+type Numbers struct {
+	Small    int8       `csv:"small"`
+	Count    uint16     `csv:"count"`
+	Big      uint64     `csv:"big"`
+	Ratio    float32    `csv:"ratio"`
+	Signal   complex128 `csv:"signal"`
+	Checksum []byte     `csv:"checksum" encoding:"hex"`
+	Payload  []byte     `csv:"payload"`
+	Total    *big.Int   `csv:"total"`
+	Price    *big.Float `csv:"price"`
+	Share    *big.Rat   `csv:"share"`
+}
+
+func TestNumericFields_RoundTrip(t *testing.T) {
+	total, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	price, _, _ := big.ParseFloat("19.99", 10, 64, big.ToNearestEven)
+	numbers := []Numbers{
+		{
+			Small:    -128,
+			Count:    65535,
+			Big:      18446744073709551615,
+			Ratio:    0.25,
+			Signal:   complex(1.5, -2),
+			Checksum: []byte{0xde, 0xad, 0xbe, 0xef},
+			Payload:  []byte("hello"),
+			Total:    total,
+			Price:    price,
+			Share:    big.NewRat(1, 3),
+		},
+	}
+
+	// Write the records
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Numbers](&buf)
+	if err := encoder.EncodeAll(numbers); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "small,count,big,ratio,signal,checksum,payload,total,price,share\n" +
+		"-128,65535,18446744073709551615,0.25,(1.5-2i),deadbeef,aGVsbG8=,123456789012345678901234567890,19.99,1/3\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Read them back
+	records, err := csvutils.ReadAll[Numbers](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	record := records[0]
+	if record.Small != -128 || record.Count != 65535 || record.Big != 18446744073709551615 ||
+		record.Ratio != 0.25 || record.Signal != complex(1.5, -2) {
+		t.Errorf("numeric fields do not match: %+v", record)
+	}
+	if !reflect.DeepEqual(record.Checksum, numbers[0].Checksum) || !reflect.DeepEqual(record.Payload, numbers[0].Payload) {
+		t.Errorf("byte fields do not match: %v %v", record.Checksum, record.Payload)
+	}
+	if record.Total.Cmp(total) != 0 || record.Price.Text('g', -1) != "19.99" || record.Share.Cmp(big.NewRat(1, 3)) != 0 {
+		t.Errorf("big fields do not match: %v %v %v", record.Total, record.Price, record.Share)
+	}
+}
+
+func TestNumericFields_Overflow(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "int8", data: "small\n128\n"},
+		{name: "uint16", data: "count\n65536\n"},
+		{name: "negative uint", data: "count\n-1\n"},
+		{name: "float32", data: "ratio\n1e39\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := csvutils.ReadAll[Numbers](strings.NewReader(tt.data))
+			var numErr *strconv.NumError
+			if !errors.As(err, &numErr) {
+				t.Errorf("expected a *strconv.NumError, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestNumericFields_BasePrefixes(t *testing.T) {
+	data := "small,count,total\n0x7f,0b101,0xff\n"
+
+	// Base prefixes are rejected by default
+	if _, err := csvutils.ReadAll[Numbers](strings.NewReader(data)); err == nil {
+		t.Errorf("expected an error without WithBasePrefixes")
+	}
+
+	records, err := csvutils.ReadAll[Numbers](strings.NewReader(data), csvutils.WithBasePrefixes())
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if records[0].Small != 127 || records[0].Count != 5 || records[0].Total.Int64() != 255 {
+		t.Errorf("prefixed values do not match: %+v", records[0])
+	}
+}
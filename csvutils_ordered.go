@@ -0,0 +1,99 @@
+package csvutils
+
+import "context"
+
+const defaultOrderedWindow = 1024
+
+// orderedDelivery hands records decoded out of order by the worker pool to a sink
+// strictly in file order. Every record is numbered by the reader; results arriving ahead
+// of their turn wait in a reorder buffer that holds at most window records.
+type orderedDelivery struct {
+	ctx     context.Context
+	sink    func(record interface{}, raw []byte, line int) error
+	onError func(line int, raw []byte, err error)
+
+	slots   chan struct{}
+	results chan orderedResult
+	done    chan struct{}
+}
+
+type orderedResult struct {
+	seq    int
+	line   int
+	raw    []byte
+	record interface{}
+	err    error
+}
+
+func newOrderedDelivery(ctx context.Context, window int, sink func(record interface{}, raw []byte, line int) error, onError func(line int, raw []byte, err error)) *orderedDelivery {
+	if window <= 0 {
+		window = defaultOrderedWindow
+	}
+	d := &orderedDelivery{
+		ctx:     ctx,
+		sink:    sink,
+		onError: onError,
+		slots:   make(chan struct{}, window),
+		results: make(chan orderedResult, window),
+		done:    make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// acquire reserves a slot in the reorder window for the next record. It blocks while
+// the window is full and returns false once the context is done.
+func (d *orderedDelivery) acquire() bool {
+	select {
+	case d.slots <- struct{}{}:
+		return true
+	case <-d.ctx.Done():
+		return false
+	}
+}
+
+// deliver is called by a worker once the record with the given sequence number has been
+// decoded, or skipped because the read was cancelled.
+func (d *orderedDelivery) deliver(result orderedResult) {
+	d.results <- result
+}
+
+// close waits until every acquired record has been delivered. It must only be called
+// after all workers have finished.
+func (d *orderedDelivery) close() {
+	close(d.results)
+	<-d.done
+}
+
+func (d *orderedDelivery) run() {
+	defer close(d.done)
+
+	pending := make(map[int]orderedResult)
+	next := 0
+	for result := range d.results {
+		pending[result.seq] = result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			d.handle(result)
+			<-d.slots
+		}
+	}
+}
+
+func (d *orderedDelivery) handle(result orderedResult) {
+	if d.ctx.Err() != nil {
+		return
+	}
+	err := result.err
+	if err == nil {
+		err = d.sink(result.record, result.raw, result.line)
+	}
+	if err != nil {
+		d.onError(result.line, result.raw, err)
+	}
+}
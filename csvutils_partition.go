@@ -0,0 +1,87 @@
+package csvutils
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+)
+
+const defaultPartitionQueue = 1024
+
+// PartitionKeyFunc returns the partition key of a decoded record, e.g. an account ID.
+type PartitionKeyFunc func(record interface{}) string
+
+// partitionDispatcher runs the handler on one goroutine per partition. Records are
+// dispatched in file order and every partition handles its queue one record at a time,
+// so records with the same key are handled in file order. Dispatching blocks while the
+// queue of the partition is full, so a slow partition only holds up the others once it
+// has queue records waiting.
+type partitionDispatcher struct {
+	ctx     context.Context
+	key     PartitionKeyFunc
+	handler RecordHandlerContext
+	onError func(line int, raw []byte, err error)
+	queues  []chan partitionTask
+	wg      sync.WaitGroup
+}
+
+type partitionTask struct {
+	record interface{}
+	raw    []byte
+	line   int
+}
+
+func newPartitionDispatcher(ctx context.Context, partitions int32, queue int, key PartitionKeyFunc, handler RecordHandlerContext, onError func(line int, raw []byte, err error)) *partitionDispatcher {
+	if partitions < 1 {
+		partitions = 1
+	}
+	if queue <= 0 {
+		queue = defaultPartitionQueue
+	}
+	d := &partitionDispatcher{
+		ctx:     ctx,
+		key:     key,
+		handler: handler,
+		onError: onError,
+		queues:  make([]chan partitionTask, partitions),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan partitionTask, queue)
+		d.wg.Add(1)
+		go d.run(d.queues[i])
+	}
+	return d
+}
+
+// dispatch queues the record on the partition owning its key. It blocks while that
+// queue is full and drops the record once the context is done.
+func (d *partitionDispatcher) dispatch(record interface{}, raw []byte, line int) error {
+	hash := fnv.New32a()
+	hash.Write([]byte(d.key(record)))
+	select {
+	case d.queues[hash.Sum32()%uint32(len(d.queues))] <- partitionTask{record: record, raw: raw, line: line}:
+	case <-d.ctx.Done():
+	}
+	return nil
+}
+
+func (d *partitionDispatcher) run(queue <-chan partitionTask) {
+	defer d.wg.Done()
+	for task := range queue {
+		if d.ctx.Err() != nil {
+			continue
+		}
+		if err := callHandler(d.ctx, d.handler, task.record, task.line); err != nil {
+			d.onError(task.line, task.raw, err)
+		}
+	}
+}
+
+// close waits for every partition to finish its queued records. It must only be called
+// once no more records are dispatched.
+func (d *partitionDispatcher) close() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
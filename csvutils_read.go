@@ -2,11 +2,17 @@ package csvutils
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"encoding"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"reflect"
+	"slices"
 	"strconv"
 
 	"github.com/vd09/gr_worker/worker_pool"
@@ -14,12 +20,49 @@ import (
 
 type RecordHandler func(interface{}) error
 
+// RecordHandlerContext is a RecordHandler that also receives the context of the read,
+// so long-running handlers can abort once it is cancelled.
+type RecordHandlerContext func(context.Context, interface{}) error
+
 type csvOptions struct {
-	handler     RecordHandler
-	concurrency int32
+	handler       RecordHandlerContext
+	concurrency   int32
+	orderedOutput bool
+	orderedWindow int
+	partitionKey  PartitionKeyFunc
+	partitionSize int
+	dialect       Dialect
+	timeLayout    string
+	converters    *Converters
+	basePrefixes  bool
+	nullTokens    []string
+	repeatFormat  string
+	repeatStart   int
+	separator     string
+	headerMatcher HeaderMatcher
+	strictHeaders bool
+	noHeader      bool
+	header        []string
+	skipLines     int
+	headerFunc    func(row []string) bool
+	headerRows    int
+	errorPolicy   ErrorPolicy
+	rejects       io.Writer
+	maxErrors     int
+	// writing is set by writers, whose leaf types need a marshaling path instead of
+	// an unmarshaling one.
+	writing bool
 }
 
 func WithHandler(handler RecordHandler) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.handler = func(_ context.Context, record interface{}) error {
+			return handler(record)
+		}
+	}
+}
+
+func WithHandlerContext(handler RecordHandlerContext) func(*csvOptions) {
 	return func(opts *csvOptions) {
 		opts.handler = handler
 	}
@@ -31,10 +74,42 @@ func WithConcurrency(concurrency int32) func(*csvOptions) {
 	}
 }
 
+// WithOrderedOutput delivers records to the handler strictly in file order while they
+// are still decoded concurrently. At most window records are buffered waiting for an
+// earlier record to finish; a window <= 0 uses defaultOrderedWindow.
+func WithOrderedOutput(window int) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.orderedOutput = true
+		opts.orderedWindow = window
+	}
+}
+
+// WithPartitionKey routes every record to one of concurrency partitions based on the key
+// returned by keyFunc. Records sharing a key are handled by the same worker in file order,
+// while different partitions are handled in parallel. Every partition queues up to
+// 1024 records, see WithPartitionQueue, before it holds up the others.
+func WithPartitionKey(keyFunc PartitionKeyFunc) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.partitionKey = keyFunc
+	}
+}
+
+// WithPartitionQueue sets how many records every partition of WithPartitionKey queues
+// before reading waits for it; a size <= 0 uses defaultPartitionQueue.
+func WithPartitionQueue(size int) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.partitionSize = size
+	}
+}
+
 func newCsvOptions(options []func(*csvOptions)) *csvOptions {
 	opts := &csvOptions{
-		handler:     nil,
-		concurrency: 1,
+		handler:       nil,
+		concurrency:   1,
+		nullTokens:    []string{""},
+		repeatFormat:  defaultRepeatFormat,
+		separator:     defaultSeparator,
+		headerMatcher: MatchExact,
 	}
 
 	for _, option := range options {
@@ -43,9 +118,63 @@ func newCsvOptions(options []func(*csvOptions)) *csvOptions {
 	return opts
 }
 
+// ReadCSV reads the CSV file at filePath and passes every record, decoded into a new
+// value of recordType, to the configured handler. Reading stops at the first failing
+// record; the returned error joins every record error reported by the workers, each
+// annotated with its line number and column.
 func ReadCSV(filePath string, recordType interface{}, options ...func(*csvOptions)) error {
+	return ReadCSVContext(context.Background(), filePath, recordType, options...)
+}
+
+// ReadCSVContext is like ReadCSV but stops reading once ctx is cancelled or its deadline
+// expires. Records already queued are drained without being processed and ctx.Err() is
+// returned. The context is passed to handlers registered with WithHandlerContext.
+func ReadCSVContext(ctx context.Context, filePath string, recordType interface{}, options ...func(*csvOptions)) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadCSVFromReaderContext(ctx, file, recordType, options...)
+}
+
+// ReadCSVFromReader is like ReadCSV but reads the CSV data from r, e.g. an HTTP request
+// body or a pipe.
+func ReadCSVFromReader(r io.Reader, recordType interface{}, options ...func(*csvOptions)) error {
+	return ReadCSVFromReaderContext(context.Background(), r, recordType, options...)
+}
+
+// ReadCSVFromFS is like ReadCSV but opens the named file from fsys, e.g. an embed.FS or
+// a testing/fstest.MapFS.
+func ReadCSVFromFS(fsys fs.FS, name string, recordType interface{}, options ...func(*csvOptions)) error {
+	return ReadCSVFromFSContext(context.Background(), fsys, name, recordType, options...)
+}
+
+// ReadCSVFromFSContext is the context-aware variant of ReadCSVFromFS.
+func ReadCSVFromFSContext(ctx context.Context, fsys fs.FS, name string, recordType interface{}, options ...func(*csvOptions)) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadCSVFromReaderContext(ctx, file, recordType, options...)
+}
+
+// ReadCSVFromReaderContext is the context-aware variant of ReadCSVFromReader.
+func ReadCSVFromReaderContext(ctx context.Context, r io.Reader, recordType interface{}, options ...func(*csvOptions)) error {
 	csvOptions := newCsvOptions(options)
 
+	elemType, err := recordElemType(recordType)
+	if err != nil {
+		return err
+	}
+	reader, err := newRecordReader(r, elemType, csvOptions)
+	if err != nil {
+		return err
+	}
+
 	pool, err := worker_pool.NewWorkerPoolAdapter(
 		worker_pool.WithMaxWorkers(csvOptions.concurrency),
 		worker_pool.WithMinWorkers(csvOptions.concurrency),
@@ -53,151 +182,460 @@ func ReadCSV(filePath string, recordType interface{}, options ...func(*csvOption
 	if err != nil {
 		return fmt.Errorf("failed to create worker pool: %w", err)
 	}
-	defer pool.WaitAndStop()
 
-	file, err := os.Open(filePath)
+	// Cancel in-flight handlers as soon as any record fails.
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs, err := newRecordErrors(csvOptions, reader.headers)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		pool.WaitAndStop()
+		return err
+	}
+	onError := func(line int, raw []byte, err error) {
+		if errs.add(line, raw, err) {
+			cancel()
+		}
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(bufio.NewReader(file))
+	// Partitioned reads decode records concurrently and hand them over in file order
+	// to the partition workers, which keeps the order within every partition.
+	var ordered *orderedDelivery
+	var partitions *partitionDispatcher
+	if csvOptions.orderedOutput || csvOptions.partitionKey != nil {
+		sink := func(record interface{}, raw []byte, line int) error {
+			return callHandler(readCtx, csvOptions.handler, record, line)
+		}
+		if csvOptions.partitionKey != nil {
+			partitions = newPartitionDispatcher(readCtx, csvOptions.concurrency, csvOptions.partitionSize, csvOptions.partitionKey, csvOptions.handler, onError)
+			sink = partitions.dispatch
+		}
+		ordered = newOrderedDelivery(readCtx, csvOptions.orderedWindow, sink, onError)
+	}
 
-	headers, err := reader.Read()
+	for seq := 0; !errs.stopped() && readCtx.Err() == nil; seq++ {
+		record, line, err := reader.read()
+		raw := reader.rawInput()
+		if err != nil {
+			var parseErr *ParseError
+			switch {
+			case err == io.EOF:
+			case errors.As(err, &parseErr):
+				// The reader resumes at the next record after a malformed one. Ordered
+				// reads fail it in its turn so earlier records are still delivered.
+				if ordered == nil {
+					onError(line, raw, err)
+					continue
+				}
+				if !ordered.acquire() {
+					break
+				}
+				ordered.deliver(orderedResult{seq: seq, line: line, raw: raw, err: err})
+				continue
+			default:
+				errs.fail(line, err)
+			}
+			break
+		}
+		if csvOptions.dialect.ReuseRecord {
+			record = slices.Clone(record)
+		}
+		if ordered != nil {
+			if !ordered.acquire() {
+				break
+			}
+			pool.AddTask(func() {
+				result := orderedResult{seq: seq, line: line, raw: raw}
+				if readCtx.Err() == nil {
+					recordValue := reflect.New(elemType).Elem()
+					result.err = reader.populate(recordValue, record, line)
+					result.record = recordValue.Addr().Interface()
+				}
+				ordered.deliver(result)
+			})
+			continue
+		}
+		pool.AddTask(func() {
+			if readCtx.Err() != nil {
+				return
+			}
+			if err := processRecord(readCtx, record, line, elemType, reader, csvOptions.handler); err != nil {
+				onError(line, raw, err)
+			}
+		})
+	}
+	pool.WaitAndStop()
+	if ordered != nil {
+		ordered.close()
+	}
+	if partitions != nil {
+		partitions.close()
+	}
+	// The rejects are written even when the read was cancelled.
+	err = errs.err()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+func recordElemType(recordType interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(recordType)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("recordType must be a pointer to a struct")
+	}
+	return t.Elem(), nil
+}
+
+// recordReader reads raw CSV records and holds the field plan used to map them onto
+// the record struct. The header row is consumed when the reader is created.
+type recordReader struct {
+	opts      *csvOptions
+	reader    *csv.Reader
+	raw       *rawRecorder
+	headers   []string
+	fieldInfo []fieldInfo
+}
+
+func newRecordReader(r io.Reader, elemType reflect.Type, opts *csvOptions) (*recordReader, error) {
+	buffered := bufio.NewReader(r)
+	if prefix, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	}
+	if err := opts.skipPreamble(buffered); err != nil {
+		return nil, err
+	}
+	var input io.Reader = buffered
+	var raw *rawRecorder
+	if opts.capturesRaw() {
+		raw = &rawRecorder{r: buffered}
+		input = raw
+	}
+	reader := opts.dialect.newReader(input)
+	var headers []string
+	if !opts.noHeader {
+		var err error
+		headers, err = opts.readHeader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read header: %w", err)
+		}
+	}
+	if opts.header != nil {
+		headers = opts.header
+	}
+	fieldInfo, err := bindHeader(headers, elemType, opts)
 	if err != nil {
-		return fmt.Errorf("failed to read header: %w", err)
+		return nil, err
+	}
+	if opts.strictHeaders {
+		if err := newBindReport(headers, fieldInfo, opts).Err(); err != nil {
+			return nil, fmt.Errorf("header does not match record type: %w", err)
+		}
+	}
+	if raw != nil {
+		raw.take(reader.InputOffset())
 	}
+	return &recordReader{opts: opts, reader: reader, raw: raw, headers: headers, fieldInfo: fieldInfo}, nil
+}
+
+// bindHeader builds the field plan of elemType against the header row.
+func bindHeader(headers []string, elemType reflect.Type, opts *csvOptions) ([]fieldInfo, error) {
 	columnIndex := make(map[string]int, len(headers))
 	for i, header := range headers {
-		columnIndex[header] = i
+		columnIndex[opts.headerMatcher(header)] = i
 	}
-
-	elemType := reflect.TypeOf(recordType).Elem()
-	if elemType.Kind() != reflect.Struct {
-		return fmt.Errorf("recordType must be a pointer to a struct")
+	infos, err := buildFieldInfo(elemType, columnIndex, "", []int{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build field info: %w", err)
+	}
+	infos = dropShadowed(infos)
+	// Required fields are checked once the number of repeated groups is known, so the
+	// probe past the last group does not count.
+	var missing error
+	walkLeaves(infos, func(info fieldInfo) {
+		if missing == nil && info.required && info.columnIndex < 0 {
+			missing = fmt.Errorf("missing required CSV column: %s", info.columnName)
+		}
+	})
+	if missing != nil {
+		return nil, missing
 	}
-	fieldInfo, err := buildFieldInfo(elemType, columnIndex, "", []int{})
+	return infos, nil
+}
+
+// read returns the next record together with the line it starts on, counting the lines
+// skipped with WithSkipLines. A record with the wrong number of fields is returned along
+// with its error.
+func (rr *recordReader) read() ([]string, int, error) {
+	record, err := rr.reader.Read()
 	if err != nil {
-		return fmt.Errorf("failed to build field info: %w", err)
+		if err == io.EOF {
+			return nil, 0, err
+		}
+		var csvErr *csv.ParseError
+		if errors.As(err, &csvErr) {
+			shifted := *csvErr
+			shifted.StartLine += rr.opts.skipLines
+			shifted.Line += rr.opts.skipLines
+			return record, shifted.StartLine, &ParseError{Line: shifted.StartLine, Column: shifted.Column, Err: &shifted}
+		}
+		return record, 0, fmt.Errorf("failed to read record: %w", err)
 	}
+	line, _ := rr.reader.FieldPos(0)
+	return record, line + rr.opts.skipLines, nil
+}
 
-	recordNum := 1
-	for {
-		record, err := reader.Read()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return fmt.Errorf("failed to read record at line %d: %w", recordNum, err)
+// rawInput returns the input of the record returned by the last call to read, or nil
+// unless the rejects need it.
+func (rr *recordReader) rawInput() []byte {
+	if rr.raw == nil {
+		return nil
+	}
+	return rr.raw.rawRecord(rr.reader.InputOffset(), rr.reader.Comment)
+}
+
+func processRecord(ctx context.Context, record []string, line int, elemType reflect.Type, reader *recordReader, handler RecordHandlerContext) error {
+	recordValue := reflect.New(elemType).Elem()
+	if err := reader.populate(recordValue, record, line); err != nil {
+		return err
+	}
+	return callHandler(ctx, handler, recordValue.Addr().Interface(), line)
+}
+
+func callHandler(ctx context.Context, handler RecordHandlerContext, record interface{}, line int) error {
+	if handler != nil {
+		if err := handler(ctx, record); err != nil {
+			return fmt.Errorf("line %d: handler error: %w", line, err)
 		}
-		pool.AddTask(processRecord, record, elemType, fieldInfo, csvOptions.handler)
-		recordNum++
 	}
 	return nil
 }
 
-func processRecord(record []string, elemType reflect.Type, fieldInfo []fieldInfo, handler RecordHandler) error {
-	recordValue := reflect.New(elemType).Elem()
-	initNestedPointers(recordValue)
+// populate sets the fields of recordValue from the cells of record.
+func (rr *recordReader) populate(recordValue reflect.Value, record []string, line int) error {
+	if _, err := rr.populateFields(recordValue, rr.fieldInfo, record, line); err != nil {
+		return err
+	}
+	return rr.validate(recordValue, record, line)
+}
 
+// populateFields sets the fields described by fieldInfo and reports whether any of their
+// cells held a non-null value.
+func (rr *recordReader) populateFields(recordValue reflect.Value, fieldInfo []fieldInfo, record []string, line int) (bool, error) {
+	hasValue := false
 	for _, info := range fieldInfo {
-		fieldValue := recordValue.FieldByIndex(info.index)
-		if fieldValue.Kind() == reflect.Ptr {
-			if fieldValue.IsNil() {
-				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		fieldValue := fieldByIndex(recordValue, info.index)
+		if info.elements != nil {
+			elementsSet, err := rr.populateRepeated(fieldValue, info, record, line)
+			if err != nil {
+				return false, err
 			}
-			fieldValue = fieldValue.Elem()
+			hasValue = hasValue || elementsSet
+			continue
 		}
 		var value string
 		if info.columnIndex >= 0 && info.columnIndex < len(record) {
 			value = record[info.columnIndex]
 		}
+		hasValue = hasValue || !rr.opts.isNull(value)
 		if value == "" {
 			value = info.defaultValue
 		}
-		if err := info.setter(fieldValue, value); err != nil {
-			return fmt.Errorf("failed to set field value for field %s: %w", info.fieldName, err)
+		if rr.opts.isNull(value) {
+			// Pointers and nullable types keep "missing" apart from the zero value.
+			if fieldValue.Kind() == reflect.Ptr || info.nullable {
+				fieldValue.Set(reflect.Zero(fieldValue.Type()))
+				continue
+			}
+			value = ""
 		}
-	}
-	if handler != nil {
-		if err := handler(recordValue.Addr().Interface()); err != nil {
-			return fmt.Errorf("handler error: %w", err)
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		var err error
+		if info.unmarshalCSV {
+			err = fieldValue.Addr().Interface().(CSVUnmarshaler).UnmarshalCSV(Cell{
+				Column: info.columnName,
+				Value:  value,
+				Header: rr.headers,
+				Row:    record,
+			})
+		} else {
+			err = info.setter(fieldValue, value)
+		}
+		if err != nil {
+			return false, &ParseError{
+				Line:      line,
+				Column:    info.columnIndex + 1,
+				Header:    info.columnName,
+				FieldName: info.fieldName,
+				RawValue:  value,
+				Err:       err,
+			}
 		}
 	}
-	return nil
+	return hasValue, nil
 }
 
-func initNestedPointers(v reflect.Value) {
-	if v.Kind() == reflect.Ptr {
-		if v.IsNil() {
-			v.Set(reflect.New(v.Type().Elem()))
-		}
-		v = v.Elem()
-	}
-	if v.Kind() == reflect.Struct {
-		for i := 0; i < v.NumField(); i++ {
-			initNestedPointers(v.Field(i))
+// fieldByIndex returns the nested field of v, allocating nil struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
 		}
+		v = v.Field(x)
 	}
+	return v
 }
 
-func buildFieldInfo(elemType reflect.Type, columnIndex map[string]int, parentTag string, parentFieldIndex []int) ([]fieldInfo, error) {
+// isLeafType reports whether a struct type is converted from a single column instead of
+// being flattened into nested columns. Types that convert themselves or have a converter
+// are leaves only in the direction they support, so a type that only implements
+// encoding.TextMarshaler is written as one column but read as nested columns.
+func isLeafType(t reflect.Type, opts *csvOptions) bool {
+	return t == timeType || hasConverter(t, opts) || implementsMarshaler(t, opts) || isNullableType(t)
+}
+
+func buildFieldInfo(elemType reflect.Type, columnIndex map[string]int, prefix string, parentFieldIndex []int, opts *csvOptions) ([]fieldInfo, error) {
 	var fieldInfos []fieldInfo
 	for i := 0; i < elemType.NumField(); i++ {
 		field := elemType.Field(i)
-		csvTag := field.Tag.Get("csv")
-		if csvTag == "" {
-			csvTag = field.Name
-		}
-		if parentTag != "" {
-			csvTag = parentTag + "_" + csvTag
+		if skipField(field, opts) {
+			continue
 		}
-		newFieldIndex := append(parentFieldIndex, field.Index...)
+		csvTag := prefix + columnName(field)
+		newFieldIndex := slices.Concat(parentFieldIndex, field.Index)
 
 		fieldType := field.Type
 		if fieldType.Kind() == reflect.Ptr {
 			fieldType = fieldType.Elem()
 		}
-		if fieldType.Kind() == reflect.Struct {
-			nestedFieldInfos, err := buildFieldInfo(fieldType, columnIndex, csvTag, newFieldIndex)
+		if groupType, ok := repeatedGroupType(field.Type, opts); ok {
+			info, err := buildRepeatedFieldInfo(field, groupType, columnIndex, csvTag, newFieldIndex, opts)
+			if err != nil {
+				return nil, err
+			}
+			fieldInfos = append(fieldInfos, info)
+		} else if fieldType.Kind() == reflect.Struct && !isLeafType(fieldType, opts) {
+			nestedFieldInfos, err := buildFieldInfo(fieldType, columnIndex, opts.nestedPrefix(prefix, field), newFieldIndex, opts)
 			if err != nil {
 				return nil, err
 			}
 			fieldInfos = append(fieldInfos, nestedFieldInfos...)
 		} else {
-			index, ok := columnIndex[csvTag]
-			defaultValue := field.Tag.Get("default")
+			tag := parseTag(field)
+			index, ok := columnIndex[opts.headerMatcher(csvTag)]
+			for _, alias := range tag.aliases {
+				if ok {
+					break
+				}
+				index, ok = columnIndex[opts.headerMatcher(prefix+alias)]
+			}
+			if tag.index != "" {
+				position, err := strconv.Atoi(tag.index)
+				if err != nil || position < 0 {
+					return nil, fmt.Errorf("invalid index tag %q on field %s", tag.index, field.Name)
+				}
+				index, ok = position, true
+			}
 			if !ok {
-				//if defaultValue == "" {
-				//	return nil, fmt.Errorf("missing CSV column: %s", csvTag)
-				//}
 				index = -1 // Indicate that the column is missing and should use the default value
 			}
-			setter, err := getFieldSetter(field.Type)
+			defaultValue := field.Tag.Get("default")
+			converter, _, hasConverter := lookupConverter(fieldType, opts)
+			hasConverter = hasConverter && converter.Parse != nil
+			unmarshalCSV := !hasConverter && reflect.PointerTo(fieldType).Implements(csvUnmarshalerType)
+			rules, err := parseValidateTag(field, fieldType)
 			if err != nil {
-				return nil, fmt.Errorf("unsupported field type for field %s: %w", field.Name, err)
+				return nil, err
+			}
+			var setter func(reflect.Value, string) error
+			if !unmarshalCSV {
+				var err error
+				setter, err = getFieldSetter(fieldType, field, opts)
+				if err != nil {
+					return nil, fmt.Errorf("unsupported field type for field %s: %w", field.Name, err)
+				}
 			}
 			fieldInfos = append(fieldInfos, fieldInfo{
 				fieldName:    field.Name,
+				columnName:   csvTag,
 				index:        newFieldIndex,
 				columnIndex:  index,
 				setter:       setter,
+				unmarshalCSV: unmarshalCSV,
+				nullable:     isNullableType(fieldType),
 				defaultValue: defaultValue,
+				rules:        rules,
+				required:     tag.required,
 			})
 		}
 	}
 	return fieldInfos, nil
 }
 
+// dropShadowed removes the fields hidden by a shallower field with the same column name,
+// or tied with another one, so no cell is set into two fields.
+func dropShadowed(infos []fieldInfo) []fieldInfo {
+	names := make([]string, len(infos))
+	depths := make([]int, len(infos))
+	for i, info := range infos {
+		names[i], depths[i] = info.columnName, len(info.index)
+	}
+	shadowed := shadowedColumns(names, depths)
+	visible := infos[:0:0]
+	for i, info := range infos {
+		if !shadowed[i] {
+			visible = append(visible, info)
+		}
+	}
+	return visible
+}
+
 type fieldInfo struct {
 	fieldName    string
+	columnName   string
 	index        []int
 	columnIndex  int
 	setter       func(reflect.Value, string) error
+	unmarshalCSV bool
+	nullable     bool
 	defaultValue string
+	// elements holds the field plan of every element of a repeated column group,
+	// relative to the element struct.
+	elements [][]fieldInfo
+	rules    []fieldRule
+	required bool
 }
 
-func getFieldSetter(fieldType reflect.Type) (func(reflect.Value, string) error, error) {
+func getFieldSetter(fieldType reflect.Type, field reflect.StructField, opts *csvOptions) (func(reflect.Value, string) error, error) {
+	if setter := getConverterSetter(fieldType, opts); setter != nil {
+		return setter, nil
+	}
+	if isNullableType(fieldType) {
+		return getNullableSetter(fieldType, field, opts)
+	}
+	if setter := getTimeSetter(fieldType, field, opts); setter != nil {
+		return setter, nil
+	}
+	if setter := getBigSetter(fieldType, opts); setter != nil {
+		return setter, nil
+	}
+	if reflect.PointerTo(fieldType).Implements(textUnmarshalerType) {
+		return func(v reflect.Value, s string) error {
+			return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}, nil
+	}
+	base := 10
+	if opts.basePrefixes {
+		base = 0
+	}
 	switch fieldType.Kind() {
 	case reflect.String:
 		return func(v reflect.Value, s string) error { v.SetString(s); return nil }, nil
@@ -206,25 +644,64 @@ func getFieldSetter(fieldType reflect.Type) (func(reflect.Value, string) error,
 			if s == "" {
 				s = "0"
 			}
-			intValue, err := strconv.ParseInt(s, 10, 64)
+			intValue, err := strconv.ParseInt(s, base, fieldType.Bits())
 			if err != nil {
 				return fmt.Errorf("error parsing int value %s: %w", s, err)
 			}
 			v.SetInt(intValue)
 			return nil
 		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(v reflect.Value, s string) error {
+			if s == "" {
+				s = "0"
+			}
+			uintValue, err := strconv.ParseUint(s, base, fieldType.Bits())
+			if err != nil {
+				return fmt.Errorf("error parsing uint value %s: %w", s, err)
+			}
+			v.SetUint(uintValue)
+			return nil
+		}, nil
 	case reflect.Float32, reflect.Float64:
 		return func(v reflect.Value, s string) error {
 			if s == "" {
 				s = "0"
 			}
-			floatValue, err := strconv.ParseFloat(s, 64)
+			floatValue, err := strconv.ParseFloat(s, fieldType.Bits())
 			if err != nil {
 				return fmt.Errorf("error parsing float value %s: %w", s, err)
 			}
 			v.SetFloat(floatValue)
 			return nil
 		}, nil
+	case reflect.Complex64, reflect.Complex128:
+		return func(v reflect.Value, s string) error {
+			if s == "" {
+				s = "0"
+			}
+			complexValue, err := strconv.ParseComplex(s, fieldType.Bits())
+			if err != nil {
+				return fmt.Errorf("error parsing complex value %s: %w", s, err)
+			}
+			v.SetComplex(complexValue)
+			return nil
+		}, nil
+	case reflect.Map:
+		return getMapSetter(fieldType, field, opts)
+	case reflect.Slice:
+		if fieldType.Elem().Kind() != reflect.Uint8 {
+			return getSliceSetter(fieldType, field, opts)
+		}
+		byteEncoding := bytesEncoding(field)
+		return func(v reflect.Value, s string) error {
+			bytesValue, err := decodeBytes(s, byteEncoding)
+			if err != nil {
+				return fmt.Errorf("error decoding %s value %s: %w", byteEncoding, s, err)
+			}
+			v.SetBytes(bytesValue)
+			return nil
+		}, nil
 	case reflect.Bool:
 		return func(v reflect.Value, s string) error {
 			if s == "" {
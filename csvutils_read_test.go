@@ -1,13 +1,19 @@
 package csvutils
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // Define structs for testing nested objects
@@ -219,3 +225,328 @@ func TestReadCSV_Concurrency(t *testing.T) {
 		t.Errorf("pointer to object records mismatch\nExpected: %v\nGot: %v", expectedMap, actualMap)
 	}
 }
+
+func TestReadCSV_ParseErrorReported(t *testing.T) {
+	csvData := `name,age,address_street,address_city
+John,30,Main St,New York
+Jane,abc,Elm St,Boston
+`
+
+	csvFilePath := createTempFile(t, csvData)
+	defer os.Remove(csvFilePath) // Clean up
+
+	// Read the CSV data
+	err := ReadCSV(csvFilePath, &Person{}, WithHandler(func(record interface{}) error { return nil }))
+	if err == nil {
+		t.Fatalf("expected an error for the invalid age value")
+	}
+
+	// Verify the error points at the offending line and column
+	if !strings.Contains(err.Error(), `line 3, column "age"`) {
+		t.Errorf("error does not contain line and column: %v", err)
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Errorf("expected error to wrap *strconv.NumError, got: %v", err)
+	}
+}
+
+func TestReadCSV_HandlerErrorStopsReading(t *testing.T) {
+	csvData := "name,age,address_street,address_city\n"
+	for i := 0; i < 1000; i++ {
+		csvData += "John," + strconv.Itoa(i) + ",Main St,New York\n"
+	}
+
+	csvFilePath := createTempFile(t, csvData)
+	defer os.Remove(csvFilePath) // Clean up
+
+	// Set up a handler that fails on the first record
+	errHandler := errors.New("handler failed")
+	var calls int
+	handler := func(record interface{}) error {
+		calls++
+		return errHandler
+	}
+
+	// Read the CSV data
+	err := ReadCSV(csvFilePath, &Person{}, WithHandler(handler))
+	if !errors.Is(err, errHandler) {
+		t.Fatalf("expected handler error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("error does not contain line number: %v", err)
+	}
+	if calls >= 1000 {
+		t.Errorf("expected reading to stop early, handler was called %d times", calls)
+	}
+}
+
+func TestReadCSVContext_Cancelled(t *testing.T) {
+	csvData := "name,age,address_street,address_city\n"
+	for i := 0; i < 1000; i++ {
+		csvData += "John," + strconv.Itoa(i) + ",Main St,New York\n"
+	}
+
+	csvFilePath := createTempFile(t, csvData)
+	defer os.Remove(csvFilePath) // Clean up
+
+	// Cancel the context from the handler after a few records
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls atomic.Int32
+	handler := func(ctx context.Context, record interface{}) error {
+		if calls.Add(1) == 10 {
+			cancel()
+		}
+		return ctx.Err()
+	}
+
+	// Read the CSV data
+	err := ReadCSVContext(ctx, csvFilePath, &Person{}, WithHandlerContext(handler), WithConcurrency(4))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
+	}
+	if calls.Load() >= 1000 {
+		t.Errorf("expected reading to stop early, handler was called %d times", calls.Load())
+	}
+}
+
+func TestReadCSVContext_DeadlineExceeded(t *testing.T) {
+	csvData := `name,age,address_street,address_city
+John,30,Main St,New York
+`
+
+	csvFilePath := createTempFile(t, csvData)
+	defer os.Remove(csvFilePath) // Clean up
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	// Read the CSV data with an expired deadline
+	err := ReadCSVContext(ctx, csvFilePath, &Person{}, WithHandler(func(record interface{}) error {
+		t.Errorf("handler must not be called after the deadline")
+		return nil
+	}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestReadCSV_OrderedOutput(t *testing.T) {
+	csvData := "name,age,address_street,address_city\n"
+	for i := 0; i < 5000; i++ {
+		csvData += "John," + strconv.Itoa(i) + ",Main St,New York\n"
+	}
+
+	csvFilePath := createTempFile(t, csvData)
+	defer os.Remove(csvFilePath) // Clean up
+
+	// Record the order in which the handler sees the records
+	var ages []int
+	handler := func(record interface{}) error {
+		ages = append(ages, record.(*Person).Age)
+		return nil
+	}
+
+	// Read the CSV data concurrently with ordered delivery
+	err := ReadCSV(csvFilePath, &Person{}, WithHandler(handler), WithConcurrency(8), WithOrderedOutput(16))
+	if err != nil {
+		t.Fatalf("error reading CSV: %v", err)
+	}
+
+	if len(ages) != 5000 {
+		t.Fatalf("expected 5000 records, got %d", len(ages))
+	}
+	for i, age := range ages {
+		if age != i {
+			t.Fatalf("record %d delivered out of order: got age %d", i, age)
+		}
+	}
+}
+
+func TestReadCSV_OrderedOutput_ParseError(t *testing.T) {
+	csvData := "name,age,address_street,address_city\n"
+	for i := 0; i < 100; i++ {
+		age := strconv.Itoa(i)
+		if i == 50 {
+			age = "abc"
+		}
+		csvData += "John," + age + ",Main St,New York\n"
+	}
+
+	csvFilePath := createTempFile(t, csvData)
+	defer os.Remove(csvFilePath) // Clean up
+
+	var ages []int
+	handler := func(record interface{}) error {
+		ages = append(ages, record.(*Person).Age)
+		return nil
+	}
+
+	// Every record before the failing one must still be delivered in order
+	err := ReadCSV(csvFilePath, &Person{}, WithHandler(handler), WithConcurrency(8), WithOrderedOutput(4))
+	if err == nil || !strings.Contains(err.Error(), "line 52") {
+		t.Fatalf("expected an error for line 52, got: %v", err)
+	}
+	if len(ages) != 50 {
+		t.Fatalf("expected 50 records before the error, got %d", len(ages))
+	}
+	for i, age := range ages {
+		if age != i {
+			t.Fatalf("record %d delivered out of order: got age %d", i, age)
+		}
+	}
+}
+
+func TestReadCSV_OrderedOutput_MalformedRecord(t *testing.T) {
+	csvData := "name,age,address_street,address_city\n"
+	for i := 0; i < 100; i++ {
+		if i == 50 {
+			csvData += "John," + strconv.Itoa(i) + "\n"
+			continue
+		}
+		csvData += "John," + strconv.Itoa(i) + ",Main St,New York\n"
+	}
+
+	var ages []int
+	handler := func(record interface{}) error {
+		ages = append(ages, record.(*Person).Age)
+		return nil
+	}
+
+	// A record with the wrong number of fields fails in file order like a decode error
+	err := ReadCSVFromReader(strings.NewReader(csvData), &Person{}, WithHandler(handler), WithConcurrency(8), WithOrderedOutput(16))
+	if err == nil || !strings.Contains(err.Error(), "line 52") {
+		t.Fatalf("expected an error for line 52, got: %v", err)
+	}
+	if len(ages) != 50 {
+		t.Fatalf("expected 50 records before the error, got %d", len(ages))
+	}
+	for i, age := range ages {
+		if age != i {
+			t.Fatalf("record %d delivered out of order: got age %d", i, age)
+		}
+	}
+}
+
+func TestReadCSV_PartitionKey(t *testing.T) {
+	csvData := "name,age,address_street,address_city\n"
+	for i := 0; i < 2000; i++ {
+		csvData += "acct-" + strconv.Itoa(i%10) + "," + strconv.Itoa(i) + ",Main St,New York\n"
+	}
+
+	csvFilePath := createTempFile(t, csvData)
+	defer os.Remove(csvFilePath) // Clean up
+
+	// Record the ages seen for every account
+	mx := sync.Mutex{}
+	agesByKey := make(map[string][]int)
+	handler := func(record interface{}) error {
+		rc := record.(*Person)
+		mx.Lock()
+		agesByKey[rc.Name] = append(agesByKey[rc.Name], rc.Age)
+		mx.Unlock()
+		return nil
+	}
+	partitionKey := func(record interface{}) string {
+		return record.(*Person).Name
+	}
+
+	// Read the CSV data concurrently, partitioned by account
+	err := ReadCSV(csvFilePath, &Person{}, WithHandler(handler), WithConcurrency(4), WithPartitionKey(partitionKey))
+	if err != nil {
+		t.Fatalf("error reading CSV: %v", err)
+	}
+
+	if len(agesByKey) != 10 {
+		t.Fatalf("expected 10 accounts, got %d", len(agesByKey))
+	}
+	for key, ages := range agesByKey {
+		if len(ages) != 200 {
+			t.Errorf("expected 200 records for %s, got %d", key, len(ages))
+		}
+		for i := 1; i < len(ages); i++ {
+			if ages[i] <= ages[i-1] {
+				t.Fatalf("records for %s delivered out of order: %d after %d", key, ages[i], ages[i-1])
+			}
+		}
+	}
+}
+
+func TestReadCSV_PartitionKey_SlowKeyDoesNotStall(t *testing.T) {
+	// Pick two keys owned by different partitions
+	partitionOf := func(key string) uint32 {
+		hash := fnv.New32a()
+		hash.Write([]byte(key))
+		return hash.Sum32() % 2
+	}
+	slow, fast := "acct-0", ""
+	for i := 1; fast == ""; i++ {
+		if key := "acct-" + strconv.Itoa(i); partitionOf(key) != partitionOf(slow) {
+			fast = key
+		}
+	}
+
+	// The slow key leads the file with more records than one queue slot
+	csvData := "name,age,address_street,address_city\n"
+	for i := 0; i < 5; i++ {
+		csvData += slow + "," + strconv.Itoa(i) + ",Main St,New York\n"
+	}
+	for i := 0; i < 50; i++ {
+		csvData += fast + "," + strconv.Itoa(i) + ",Main St,New York\n"
+	}
+
+	// The slow handler waits until every fast record was handled
+	fastDone := make(chan struct{})
+	var fastHandled atomic.Int32
+	handler := func(record interface{}) error {
+		if record.(*Person).Name == slow {
+			select {
+			case <-fastDone:
+				return nil
+			case <-time.After(5 * time.Second):
+				return errors.New("fast partition stalled behind the slow one")
+			}
+		}
+		if fastHandled.Add(1) == 50 {
+			close(fastDone)
+		}
+		return nil
+	}
+	partitionKey := func(record interface{}) string {
+		return record.(*Person).Name
+	}
+
+	err := ReadCSVFromReader(strings.NewReader(csvData), &Person{}, WithHandler(handler), WithConcurrency(2), WithPartitionKey(partitionKey))
+	if err != nil {
+		t.Fatalf("error reading CSV: %v", err)
+	}
+}
+
+func TestReadCSV_PartitionQueue(t *testing.T) {
+	csvData := "name,age,address_street,address_city\n"
+	for i := 0; i < 100; i++ {
+		csvData += "acct-" + strconv.Itoa(i%3) + "," + strconv.Itoa(i) + ",Main St,New York\n"
+	}
+
+	// A full queue holds up reading instead of dropping records
+	var handled atomic.Int32
+	handler := func(record interface{}) error {
+		time.Sleep(time.Millisecond)
+		handled.Add(1)
+		return nil
+	}
+	partitionKey := func(record interface{}) string {
+		return record.(*Person).Name
+	}
+
+	err := ReadCSVFromReader(strings.NewReader(csvData), &Person{}, WithHandler(handler), WithConcurrency(2),
+		WithPartitionKey(partitionKey), WithPartitionQueue(1))
+	if err != nil {
+		t.Fatalf("error reading CSV: %v", err)
+	}
+	if handled.Load() != 100 {
+		t.Errorf("expected 100 handled records, got %d", handled.Load())
+	}
+}
@@ -0,0 +1,220 @@
+package csvutils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+const defaultRepeatFormat = "%s_%d"
+
+// WithRepeatedColumns sets how the columns of repeated groups are named. A []Address
+// field tagged `csv:"address"` maps to the groups fmt.Sprintf(format, "address", i) for
+// i = start, start+1, ..., e.g. address_0_street and address_0_city with the default
+// format "%s_%d" and start 0.
+func WithRepeatedColumns(format string, start int) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.repeatFormat = format
+		opts.repeatStart = start
+	}
+}
+
+// repeatedGroupType returns the element struct of a slice field that maps to repeated
+// column groups, such as []Address or []*Address. Slice types that convert themselves
+// or have a converter stay in a single column.
+func repeatedGroupType(t reflect.Type, opts *csvOptions) (reflect.Type, bool) {
+	if t.Kind() != reflect.Slice {
+		return nil, false
+	}
+	if hasConverter(t, opts) || implementsMarshaler(t, opts) {
+		return nil, false
+	}
+	elemType := t.Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct || isLeafType(elemType, opts) {
+		return nil, false
+	}
+	return elemType, true
+}
+
+// repeatedMaxLen returns the number of groups declared with the `maxlen` tag.
+func repeatedMaxLen(field reflect.StructField) (int, bool, error) {
+	tag := field.Tag.Get("maxlen")
+	if tag == "" {
+		return 0, false, nil
+	}
+	maxLen, err := strconv.Atoi(tag)
+	if err != nil || maxLen < 0 {
+		return 0, false, fmt.Errorf("invalid maxlen tag %q on field %s", tag, field.Name)
+	}
+	return maxLen, true, nil
+}
+
+func (opts *csvOptions) repeatedColumnName(name string, i int) string {
+	return fmt.Sprintf(opts.repeatFormat, name, opts.repeatStart+i)
+}
+
+// buildRepeatedFieldInfo builds the plan of every element of a repeated group. Without a
+// `maxlen` tag the number of elements is the number of consecutive groups found in the
+// header.
+func buildRepeatedFieldInfo(field reflect.StructField, groupType reflect.Type, columnIndex map[string]int, csvTag string, fieldIndex []int, opts *csvOptions) (fieldInfo, error) {
+	maxLen, hasMaxLen, err := repeatedMaxLen(field)
+	if err != nil {
+		return fieldInfo{}, err
+	}
+	// Every element would bind the same position, and probing for groups would never
+	// stop.
+	if hasIndexTag(groupType, opts) {
+		return fieldInfo{}, fmt.Errorf("index tags are not supported in repeated field %s", field.Name)
+	}
+	elements := [][]fieldInfo{}
+	for i := 0; !hasMaxLen || i < maxLen; i++ {
+		elementInfo, err := buildFieldInfo(groupType, columnIndex, opts.repeatedColumnName(csvTag, i)+opts.separator, []int{}, opts)
+		if err != nil {
+			return fieldInfo{}, err
+		}
+		elementInfo = dropShadowed(elementInfo)
+		if !hasMaxLen && !anyColumnBound(elementInfo) {
+			break
+		}
+		elements = append(elements, elementInfo)
+	}
+	return fieldInfo{
+		fieldName:   field.Name,
+		columnName:  csvTag,
+		index:       fieldIndex,
+		columnIndex: -1,
+		elements:    elements,
+	}, nil
+}
+
+// hasIndexTag reports whether a field of t, or of a struct nested in it, binds by
+// position.
+func hasIndexTag(t reflect.Type, opts *csvOptions) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if skipField(field, opts) {
+			continue
+		}
+		if parseTag(field).index != "" {
+			return true
+		}
+		fieldType := field.Type
+		if groupType, ok := repeatedGroupType(fieldType, opts); ok {
+			fieldType = groupType
+		} else if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && !isLeafType(fieldType, opts) && hasIndexTag(fieldType, opts) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyColumnBound(fieldInfo []fieldInfo) bool {
+	for _, info := range fieldInfo {
+		if info.columnIndex >= 0 {
+			return true
+		}
+		for _, element := range info.elements {
+			if anyColumnBound(element) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// populateRepeated fills a repeated group slice. Trailing elements without any non-null
+// cell are dropped, so the slice is nil when every group is empty.
+func (rr *recordReader) populateRepeated(fieldValue reflect.Value, info fieldInfo, record []string, line int) (bool, error) {
+	sliceType := fieldValue.Type()
+	slice := reflect.MakeSlice(sliceType, len(info.elements), len(info.elements))
+	length := 0
+	for i, elementInfo := range info.elements {
+		element := slice.Index(i)
+		if element.Kind() == reflect.Ptr {
+			element.Set(reflect.New(sliceType.Elem().Elem()))
+			element = element.Elem()
+		}
+		hasValue, err := rr.populateFields(element, elementInfo, record, line)
+		if err != nil {
+			return false, err
+		}
+		if hasValue {
+			length = i + 1
+		}
+	}
+	if length == 0 {
+		fieldValue.Set(reflect.Zero(sliceType))
+		return false, nil
+	}
+	fieldValue.Set(slice.Slice(0, length))
+	return true, nil
+}
+
+// extractRepeatedColumns returns the columns of every group of a repeated field.
+func extractRepeatedColumns(field reflect.StructField, groupType reflect.Type, headerName string, opts *csvOptions) ([]column, error) {
+	maxLen, hasMaxLen, err := repeatedMaxLen(field)
+	if err != nil {
+		return nil, err
+	}
+	if !hasMaxLen {
+		return nil, fmt.Errorf("repeated field %s needs a maxlen tag to be written", field.Name)
+	}
+	var columns []column
+	for i := 0; i < maxLen; i++ {
+		groupColumns, err := extractColumns(groupType, opts.repeatedColumnName(headerName, i)+opts.separator, opts)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, groupColumns...)
+	}
+	return columns, nil
+}
+
+// extractRepeatedValues returns the values of every group of a repeated field, padding
+// missing elements with null values.
+func extractRepeatedValues(v reflect.Value, field reflect.StructField, groupType reflect.Type, headerName string, opts *csvOptions) ([]string, error) {
+	maxLen, _, err := repeatedMaxLen(field)
+	if err != nil {
+		return nil, err
+	}
+	if v.Len() > maxLen {
+		return nil, fmt.Errorf("repeated field %s has %d elements, more than its maxlen of %d", field.Name, v.Len(), maxLen)
+	}
+	var values []string
+	for i := 0; i < maxLen; i++ {
+		prefix := opts.repeatedColumnName(headerName, i) + opts.separator
+		var element reflect.Value
+		if i < v.Len() {
+			element = v.Index(i)
+			if element.Kind() == reflect.Ptr {
+				if element.IsNil() {
+					element = reflect.Value{}
+				} else {
+					element = element.Elem()
+				}
+			}
+		}
+		if !element.IsValid() {
+			groupHeaders, err := extractHeaders(groupType, prefix, opts)
+			if err != nil {
+				return nil, err
+			}
+			for range groupHeaders {
+				values = append(values, opts.nullToken())
+			}
+			continue
+		}
+		groupValues, err := extractValues(element, prefix, opts)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, groupValues...)
+	}
+	return values, nil
+}
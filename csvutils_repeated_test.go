@@ -0,0 +1,185 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+type Location struct {
+	Street string `csv:"street"`
+	City   string `csv:"city"`
+}
+
+type Contact struct {
+	Name      string     `csv:"name"`
+	Addresses []Location `csv:"address" maxlen:"2"`
+}
+
+func TestRepeatedColumns_RoundTrip(t *testing.T) {
+	customers := []Contact{
+		{Name: "Ann", Addresses: []Location{{Street: "1 Main St", City: "Springfield"}, {Street: "2 Side St", City: "Shelbyville"}}},
+		{Name: "Bob", Addresses: []Location{{Street: "3 High St", City: "Ogdenville"}}},
+		{Name: "Cid"},
+	}
+
+	// Write the records
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Contact](&buf)
+	if err := encoder.EncodeAll(customers); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "name,address_0_street,address_0_city,address_1_street,address_1_city\n" +
+		"Ann,1 Main St,Springfield,2 Side St,Shelbyville\n" +
+		"Bob,3 High St,Ogdenville,,\n" +
+		"Cid,,,,\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Read them back, trailing empty groups are dropped
+	records, err := csvutils.ReadAll[Contact](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if !reflect.DeepEqual(records, customers) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, customers)
+	}
+}
+
+// Itinerary converts itself, so it is stored in a single column instead of repeated groups.
+type Itinerary []Location
+
+func (t Itinerary) MarshalText() ([]byte, error) {
+	cities := make([]string, len(t))
+	for i, location := range t {
+		cities[i] = location.City
+	}
+	return []byte(strings.Join(cities, "|")), nil
+}
+
+func (t *Itinerary) UnmarshalText(text []byte) error {
+	*t = nil
+	for _, city := range strings.Split(string(text), "|") {
+		*t = append(*t, Location{City: city})
+	}
+	return nil
+}
+
+func TestRepeatedColumns_MarshalerSlice(t *testing.T) {
+	type Trip struct {
+		Name  string    `csv:"name"`
+		Stops Itinerary `csv:"t"`
+	}
+	trips := []Trip{{Name: "Ann", Stops: Itinerary{{City: "Springfield"}, {City: "Shelbyville"}}}}
+
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Trip](&buf)
+	if err := encoder.EncodeAll(trips); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if expected := "name,t\nAnn,Springfield|Shelbyville\n"; buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	records, err := csvutils.ReadAll[Trip](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if !reflect.DeepEqual(records, trips) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, trips)
+	}
+}
+
+func TestRepeatedColumns_DiscoverGroups(t *testing.T) {
+	type Shipment struct {
+		ID    string      `csv:"id"`
+		Stops []*Location `csv:"stop"`
+	}
+	data := "id,stop_1_street,stop_1_city,stop_2_street,stop_2_city,stop_3_street,stop_3_city\n" +
+		"s1,A St,Aville,,,C St,Cville\n"
+
+	// Without maxlen every consecutive group found in the header is read
+	records, err := csvutils.ReadAll[Shipment](strings.NewReader(data), csvutils.WithRepeatedColumns("%s_%d", 1))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expected := []Shipment{{
+		ID:    "s1",
+		Stops: []*Location{{Street: "A St", City: "Aville"}, {}, {Street: "C St", City: "Cville"}},
+	}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+}
+
+func TestRepeatedColumns_WriteErrors(t *testing.T) {
+	type Unbounded struct {
+		Addresses []Location `csv:"address"`
+	}
+
+	// A group without maxlen cannot be written
+	var buf bytes.Buffer
+	if err := csvutils.NewEncoder[Unbounded](&buf).Encode(Unbounded{}); err == nil || !strings.Contains(err.Error(), "maxlen") {
+		t.Errorf("Expected maxlen error, got: %v", err)
+	}
+
+	// More elements than maxlen is an error
+	customer := Contact{Addresses: make([]Location, 3)}
+	if err := csvutils.NewEncoder[Contact](&buf).Encode(customer); err == nil || !strings.Contains(err.Error(), "more than its maxlen") {
+		t.Errorf("Expected too many elements error, got: %v", err)
+	}
+}
+
+func TestRepeatedColumns_IndexTagRejected(t *testing.T) {
+	type Item struct {
+		Value string `csv:",index=1"`
+	}
+	type Order struct {
+		Items []Item `csv:"item"`
+	}
+
+	// Positional fields cannot repeat, probing for groups would never end
+	_, err := csvutils.ReadAll[Order](strings.NewReader("a,b\n"), csvutils.WithNoHeader())
+	if err == nil || !strings.Contains(err.Error(), "index tags are not supported in repeated field Items") {
+		t.Errorf("Expected index tag error, got: %v", err)
+	}
+}
+
+func TestRepeatedColumns_Required(t *testing.T) {
+	type Address struct {
+		Street string `csv:"street,required"`
+	}
+	type Person struct {
+		Addresses []Address `csv:"address"`
+	}
+
+	// Required applies to the groups found in the header, not to the probe past them
+	records, err := csvutils.ReadAll[Person](strings.NewReader("address_0_street,address_1_street\nA St,B St\n"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expected := []Person{{Addresses: []Address{{Street: "A St"}, {Street: "B St"}}}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+
+	// Groups declared with maxlen must all be present
+	type Bounded struct {
+		Addresses []Address `csv:"address" maxlen:"3"`
+	}
+	_, err = csvutils.ReadAll[Bounded](strings.NewReader("address_0_street,address_1_street\nA St,B St\n"))
+	if err == nil || !strings.Contains(err.Error(), "missing required CSV column: address_2_street") {
+		t.Errorf("Expected missing required column error, got: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package csvutils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// SniffSampleSize is the number of bytes Sniff inspects from the start of the input.
+const SniffSampleSize = 64 << 10
+
+// sniffDelimiters are the delimiters Sniff considers, in order of preference.
+var sniffDelimiters = []rune{',', ';', '\t', '|', ':'}
+
+// SniffResult describes the format detected by Sniff.
+type SniffResult struct {
+	// Dialect can be passed to WithDialect to read the sniffed data.
+	Dialect Dialect
+	// HasHeader reports whether the first row looks like a header row.
+	HasHeader bool
+	// Quoted reports whether any field in the sample is enclosed in double quotes.
+	Quoted bool
+}
+
+// Sniff samples the first SniffSampleSize bytes of r and detects the delimiter, the
+// quoting style, the line endings, a leading UTF-8 byte order mark and whether the first
+// row is a header. The returned reader replays the sample followed by the rest of r, so
+// a stream such as an HTTP body can be passed on to ReadCSVFromReader. The reader is
+// returned with the error too, so the caller can fall back to a default dialect.
+func Sniff(r io.Reader) (*SniffResult, io.Reader, error) {
+	sample, err := io.ReadAll(io.LimitReader(r, SniffSampleSize))
+	if err != nil {
+		return nil, io.MultiReader(bytes.NewReader(sample), r), fmt.Errorf("failed to read sample: %w", err)
+	}
+	replay := io.MultiReader(bytes.NewReader(sample), r)
+	truncated := len(sample) == SniffSampleSize
+
+	result := &SniffResult{}
+	if bytes.HasPrefix(sample, utf8BOM) {
+		result.Dialect.BOM = true
+		sample = sample[len(utf8BOM):]
+	}
+	if len(bytes.TrimSpace(sample)) == 0 {
+		return nil, replay, errors.New("no data to sniff")
+	}
+
+	// Drop the last line of a truncated sample, it is most likely incomplete.
+	if truncated {
+		if i := bytes.LastIndexByte(sample, '\n'); i > 0 {
+			sample = sample[:i+1]
+		}
+	}
+
+	crlf := bytes.Count(sample, []byte("\r\n"))
+	result.Dialect.UseCRLF = crlf > 0 && crlf >= bytes.Count(sample, []byte("\n"))-crlf
+	result.Dialect.Comma = sniffDelimiter(sample)
+	result.Quoted = sniffQuoted(sample, result.Dialect.Comma)
+
+	rows, err := sniffRows(sample, &result.Dialect)
+	if err != nil {
+		return nil, replay, err
+	}
+	result.HasHeader = sniffHeader(rows)
+	return result, replay, nil
+}
+
+// sniffDelimiter picks the candidate that occurs the same, non-zero number of times on
+// the most lines. Delimiters inside quoted fields are ignored.
+func sniffDelimiter(sample []byte) rune {
+	best, bestScore, bestCount := sniffDelimiters[0], 0, 0
+	for _, delimiter := range sniffDelimiters {
+		frequencies := make(map[int]int)
+		for _, count := range countPerLine(sample, delimiter) {
+			frequencies[count]++
+		}
+		for count, lines := range frequencies {
+			if count == 0 {
+				continue
+			}
+			if lines > bestScore || (lines == bestScore && count > bestCount) {
+				best, bestScore, bestCount = delimiter, lines, count
+			}
+		}
+	}
+	return best
+}
+
+func countPerLine(sample []byte, delimiter rune) []int {
+	var counts []int
+	count, inQuotes, lineEmpty := 0, false, true
+	for _, c := range string(sample) {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == delimiter:
+			count++
+		case c == '\n':
+			if !lineEmpty {
+				counts = append(counts, count)
+			}
+			count, lineEmpty = 0, true
+			continue
+		case c == '\r':
+			continue
+		}
+		lineEmpty = false
+	}
+	if !lineEmpty {
+		counts = append(counts, count)
+	}
+	return counts
+}
+
+// sniffQuoted reports whether a field starts with a double quote.
+func sniffQuoted(sample []byte, delimiter rune) bool {
+	return bytes.HasPrefix(sample, []byte{'"'}) ||
+		bytes.Contains(sample, []byte(string(delimiter)+`"`)) ||
+		bytes.Contains(sample, []byte("\n\""))
+}
+
+// sniffRows parses the sample, switching to lazy quotes if it is not strictly quoted.
+func sniffRows(sample []byte, dialect *Dialect) ([][]string, error) {
+	parse := func() ([][]string, error) {
+		reader := dialect.newReader(bytes.NewReader(sample))
+		reader.FieldsPerRecord = -1
+		return reader.ReadAll()
+	}
+	rows, err := parse()
+	if errors.Is(err, csv.ErrBareQuote) || errors.Is(err, csv.ErrQuote) {
+		dialect.LazyQuotes = true
+		rows, err = parse()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sample: %w", err)
+	}
+	return rows, nil
+}
+
+// sniffHeader votes per column: a column whose data cells are all numeric, or all of one
+// length, votes for a header when the first cell differs from them and against it
+// otherwise. Without any votes the first row is a header if its cells look like names.
+func sniffHeader(rows [][]string) bool {
+	if len(rows) == 0 {
+		return false
+	}
+	header := rows[0]
+
+	votes := 0
+	for column, cell := range header {
+		values, allNumeric, length := 0, true, -1
+		for _, row := range rows[1:] {
+			if column >= len(row) {
+				continue
+			}
+			values++
+			if !isNumeric(row[column]) {
+				allNumeric = false
+			}
+			switch {
+			case length == -1:
+				length = len(row[column])
+			case length != len(row[column]):
+				length = -2
+			}
+		}
+		switch {
+		case values == 0:
+		case allNumeric:
+			if isNumeric(cell) {
+				votes--
+			} else {
+				votes++
+			}
+		case length >= 0:
+			if len(cell) == length {
+				votes--
+			} else {
+				votes++
+			}
+		}
+	}
+	if votes != 0 {
+		return votes > 0
+	}
+	return looksLikeNames(header)
+}
+
+// looksLikeNames reports whether all cells are non-empty, non-numeric and unique.
+func looksLikeNames(cells []string) bool {
+	seen := make(map[string]bool, len(cells))
+	for _, cell := range cells {
+		if cell == "" || isNumeric(cell) || seen[cell] {
+			return false
+		}
+		seen[cell] = true
+	}
+	return true
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
@@ -0,0 +1,112 @@
+package csvutils_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected csvutils.SniffResult
+	}{
+		{
+			name: "semicolon with BOM and CRLF",
+			data: "\xEF\xBB\xBFName;Age;Email\r\nAlice;30;alice@example.com\r\nBob;35;bob@example.com\r\n",
+			expected: csvutils.SniffResult{
+				Dialect:   csvutils.Dialect{Comma: ';', UseCRLF: true, BOM: true},
+				HasHeader: true,
+			},
+		},
+		{
+			name: "tab separated without header",
+			data: "1\t2.5\tx\n2\t3.5\ty\n3\t4.5\tz\n",
+			expected: csvutils.SniffResult{
+				Dialect: csvutils.Dialect{Comma: '\t'},
+			},
+		},
+		{
+			name: "quoted fields containing other delimiters",
+			data: "id,comment\n1,\"a;b;c|d\"\n2,\"e;f;g|h\"\n",
+			expected: csvutils.SniffResult{
+				Dialect:   csvutils.Dialect{Comma: ','},
+				HasHeader: true,
+				Quoted:    true,
+			},
+		},
+		{
+			name: "sloppy quotes",
+			data: "id|comment\n1|a \"quoted\" word\n2|plain\n",
+			expected: csvutils.SniffResult{
+				Dialect:   csvutils.Dialect{Comma: '|', LazyQuotes: true},
+				HasHeader: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := csvutils.Sniff(strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Sniff returned error: %v", err)
+			}
+			if !reflect.DeepEqual(*result, tt.expected) {
+				t.Errorf("Sniff result does not match expected. Got: %+v, Expected: %+v", *result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSniff_ReadWithDetectedDialect(t *testing.T) {
+	var data strings.Builder
+	data.WriteString("\xEF\xBB\xBFName;Age;Email\r\n")
+	for data.Len() <= csvutils.SniffSampleSize {
+		data.WriteString("Alice;30;alice@example.com\r\n")
+	}
+	rows := strings.Count(data.String(), "\r\n") - 1
+
+	// Sniff a stream that cannot be rewound, larger than the sample
+	stream := struct{ io.Reader }{strings.NewReader(data.String())}
+	result, replay, err := csvutils.Sniff(stream)
+	if err != nil {
+		t.Fatalf("Sniff returned error: %v", err)
+	}
+
+	// Read the replayed data with the sniffed dialect
+	records, err := csvutils.ReadAll[TestStruct](replay, csvutils.WithDialect(result.Dialect))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if len(records) != rows {
+		t.Fatalf("Expected %d records, got %d", rows, len(records))
+	}
+
+	expected := TestStruct{Name: "Alice", Age: 30, Email: "alice@example.com"}
+	if records[0] != expected || records[len(records)-1] != expected {
+		t.Errorf("Parsed records do not match expected. Got: %v and %v, Expected: %v", records[0], records[len(records)-1], expected)
+	}
+}
+
+func TestSniff_Empty(t *testing.T) {
+	_, replay, err := csvutils.Sniff(strings.NewReader(" \n"))
+	if err == nil {
+		t.Errorf("expected an error for empty input")
+	}
+
+	// The input is still replayed so the caller can read it with a default dialect
+	if replay == nil {
+		t.Fatalf("expected a reader along with the error")
+	}
+	data, err := io.ReadAll(replay)
+	if err != nil {
+		t.Fatalf("failed to read replay: %v", err)
+	}
+	if string(data) != " \n" {
+		t.Errorf("Replayed data does not match input. Got: %q", data)
+	}
+}
@@ -0,0 +1,119 @@
+package csvutils
+
+import (
+	"reflect"
+	"strings"
+)
+
+const defaultSeparator = "_"
+
+// fieldTag is the parsed `csv` struct tag: a column name followed by comma separated
+// options, e.g. `csv:"email,required,alias=e-mail|mail"`, `csv:",index=3"` or
+// `csv:",inline"`. The tag `csv:"-"` skips the field. The reader and the writer both
+// read tags through parseTag.
+type fieldTag struct {
+	name      string
+	skip      bool
+	omitEmpty bool
+	required  bool
+	aliases   []string
+	index     string
+	inline    bool
+	prefix    string
+	hasPrefix bool
+}
+
+func parseTag(field reflect.StructField) fieldTag {
+	raw := field.Tag.Get("csv")
+	if raw == "-" {
+		return fieldTag{skip: true}
+	}
+	name, options, _ := strings.Cut(raw, ",")
+	tag := fieldTag{name: name, index: field.Tag.Get("index")}
+	for _, option := range strings.Split(options, ",") {
+		switch key, value, _ := strings.Cut(option, "="); key {
+		case "omitempty":
+			tag.omitEmpty = true
+		case "required":
+			tag.required = true
+		case "alias":
+			tag.aliases = strings.Split(value, "|")
+		case "index":
+			tag.index = value
+		case "inline":
+			tag.inline = true
+		case "prefix":
+			tag.prefix, tag.hasPrefix = value, true
+		}
+	}
+	return tag
+}
+
+// skipField reports whether a field is left out of the CSV columns: fields tagged
+// `csv:"-"` and unexported fields, except embedded structs whose exported fields are
+// promoted.
+func skipField(field reflect.StructField, opts *csvOptions) bool {
+	if parseTag(field).skip {
+		return true
+	}
+	if field.IsExported() {
+		return false
+	}
+	return !field.Anonymous || field.Type.Kind() != reflect.Struct || isLeafType(field.Type, opts)
+}
+
+// columnName returns the column name of a field: the name in its `csv` tag or, without
+// one, its field name.
+func columnName(field reflect.StructField) string {
+	if name := parseTag(field).name; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// shadowedColumns reports which columns are hidden by another column of the same name,
+// following the rules encoding/json applies to the fields of embedded structs: the
+// column at the shallowest depth wins and columns tied at that depth are all dropped.
+func shadowedColumns(names []string, depths []int) []bool {
+	type key struct {
+		name  string
+		depth int
+	}
+	shallowest := make(map[string]int, len(names))
+	counts := make(map[key]int, len(names))
+	for i, name := range names {
+		if depth, ok := shallowest[name]; !ok || depths[i] < depth {
+			shallowest[name] = depths[i]
+		}
+		counts[key{name, depths[i]}]++
+	}
+	shadowed := make([]bool, len(names))
+	for i, name := range names {
+		shadowed[i] = depths[i] > shallowest[name] || counts[key{name, depths[i]}] > 1
+	}
+	return shadowed
+}
+
+// WithNestingSeparator sets the separator joining the column name of a nested struct
+// field to the names of its columns. The default is "_", e.g. address_street.
+func WithNestingSeparator(separator string) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.separator = separator
+	}
+}
+
+// nestedPrefix returns the prefix of the columns of a nested struct field. Like
+// encoding/json, embedded structs without a tag name are flattened into the parent.
+// The `inline` tag option flattens any struct field and `prefix=x_` uses x_ in place of
+// the field's column name and the separator.
+func (opts *csvOptions) nestedPrefix(prefix string, field reflect.StructField) string {
+	tag := parseTag(field)
+	switch {
+	case tag.hasPrefix:
+		return prefix + tag.prefix
+	case tag.inline, field.Anonymous && tag.name == "":
+		return prefix
+	default:
+		return prefix + columnName(field) + opts.separator
+	}
+}
@@ -0,0 +1,211 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+type AuditFields struct {
+	CreatedBy string `csv:"created_by"`
+	UpdatedBy string `csv:"updated_by"`
+}
+
+type Invoice struct {
+	AuditFields
+	Number  string      `csv:"number"`
+	Billing Location    `csv:"billing,inline"`
+	Payer   AuditFields `csv:",prefix=payer."`
+}
+
+func TestEmbeddedStruct_Flattened(t *testing.T) {
+	invoices := []Invoice{{
+		AuditFields: AuditFields{CreatedBy: "ann", UpdatedBy: "bob"},
+		Number:      "INV-1",
+		Billing:     Location{Street: "1 Main St", City: "Springfield"},
+		Payer:       AuditFields{CreatedBy: "cid"},
+	}}
+
+	// Write the records
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Invoice](&buf)
+	if err := encoder.EncodeAll(invoices); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "created_by,updated_by,number,street,city,payer.created_by,payer.updated_by\n" +
+		"ann,bob,INV-1,1 Main St,Springfield,cid,\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Read them back
+	records, err := csvutils.ReadAll[Invoice](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if !reflect.DeepEqual(records, invoices) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, invoices)
+	}
+}
+
+func TestNestingSeparator(t *testing.T) {
+	type Order struct {
+		ID       string   `csv:"id"`
+		Shipping Location `csv:"shipping"`
+		Audit    AuditFields
+	}
+	data := "id,shipping.street,shipping.city,Audit.created_by\n" +
+		"o1,2 Side St,Shelbyville,ann\n"
+
+	// Nested columns are joined with the configured separator
+	records, err := csvutils.ReadAll[Order](strings.NewReader(data), csvutils.WithNestingSeparator("."))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expected := []Order{{
+		ID:       "o1",
+		Shipping: Location{Street: "2 Side St", City: "Shelbyville"},
+		Audit:    AuditFields{CreatedBy: "ann"},
+	}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+}
+
+type NoteBase struct {
+	ID   string `csv:"id"`
+	Note string `csv:"note"`
+}
+
+type NoteExtra struct {
+	Note string `csv:"note"`
+}
+
+type Annotated struct {
+	NoteBase
+	Note string `csv:"note"`
+}
+
+type AmbiguousNote struct {
+	NoteBase
+	NoteExtra
+}
+
+func TestEmbeddedStruct_Shadowing(t *testing.T) {
+	// The shallower field wins over the embedded one
+	annotated := []Annotated{{NoteBase: NoteBase{ID: "a1", Note: "hidden"}, Note: "shown"}}
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Annotated](&buf)
+	if err := encoder.EncodeAll(annotated); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	expected := "id,note\na1,shown\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+	records, err := csvutils.ReadAll[Annotated](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expectedRecords := []Annotated{{NoteBase: NoteBase{ID: "a1"}, Note: "shown"}}
+	if !reflect.DeepEqual(records, expectedRecords) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expectedRecords)
+	}
+
+	// Fields tied at the same depth are dropped
+	ambiguous := []AmbiguousNote{{NoteBase: NoteBase{ID: "a2", Note: "one"}, NoteExtra: NoteExtra{Note: "two"}}}
+	buf.Reset()
+	ambiguousEncoder := csvutils.NewEncoder[AmbiguousNote](&buf)
+	if err := ambiguousEncoder.EncodeAll(ambiguous); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := ambiguousEncoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	expected = "id\na2\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+	ambiguousRecords, err := csvutils.ReadAll[AmbiguousNote](strings.NewReader("id,note\na2,one\n"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expectedAmbiguous := []AmbiguousNote{{NoteBase: NoteBase{ID: "a2"}}}
+	if !reflect.DeepEqual(ambiguousRecords, expectedAmbiguous) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", ambiguousRecords, expectedAmbiguous)
+	}
+}
+
+type tracking struct {
+	Source string `csv:"source"`
+}
+
+type Subscriber struct {
+	tracking
+	Email    string `csv:"email,required,alias=e-mail|mail"`
+	Visits   int    `csv:"visits,omitempty"`
+	Password string `csv:"-"`
+	internal string
+}
+
+func TestTagOptions_RoundTrip(t *testing.T) {
+	subscribers := []Subscriber{
+		{tracking: tracking{Source: "ads"}, Email: "ann@example.com", Visits: 3, Password: "secret", internal: "x"},
+		{Email: "bob@example.com"},
+	}
+
+	// Write the records, skipped and unexported fields have no column
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Subscriber](&buf)
+	if err := encoder.EncodeAll(subscribers); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "source,email,visits\n" +
+		"ads,ann@example.com,3\n" +
+		",bob@example.com,\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Read them back
+	records, err := csvutils.ReadAll[Subscriber](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	subscribers[0].Password, subscribers[0].internal = "", ""
+	if !reflect.DeepEqual(records, subscribers) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, subscribers)
+	}
+}
+
+func TestTagOptions_AliasAndRequired(t *testing.T) {
+	// An alias binds the column when the primary name is absent
+	records, err := csvutils.ReadAll[Subscriber](strings.NewReader("mail,Password\nann@example.com,secret\n"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	expected := []Subscriber{{Email: "ann@example.com"}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+
+	// A missing required column fails before any record is read
+	_, err = csvutils.ReadAll[Subscriber](strings.NewReader("source,visits\nads,1\n"))
+	if err == nil || !strings.Contains(err.Error(), "missing required CSV column: email") {
+		t.Errorf("Expected missing required column error, got: %v", err)
+	}
+}
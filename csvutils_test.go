@@ -1,11 +1,16 @@
 package csvutils_test
 
 import (
+	"bytes"
 	"encoding/csv"
+	"errors"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/vd09/csvutils" // import your package path here
 )
@@ -153,3 +158,118 @@ Bob,35,`)
 		t.Errorf("Parsed records do not match expected. Got: %v, Expected: %v", records, expected)
 	}
 }
+
+func TestReadCSVFromReader(t *testing.T) {
+	// Sample CSV data streamed from memory
+	data := strings.NewReader(`Name,Age,Email
+Alice,30,alice@example.com
+Bob,35,bob@example.com`)
+
+	// Create variables to store records
+	var records []TestStruct
+	handler := func(record interface{}) error {
+		records = append(records, *record.(*TestStruct))
+		return nil
+	}
+
+	// Call ReadCSVFromReader function with the reader
+	err := csvutils.ReadCSVFromReader(data, &TestStruct{}, csvutils.WithHandler(handler))
+	if err != nil {
+		t.Fatalf("ReadCSVFromReader returned error: %v", err)
+	}
+
+	// Define expected records
+	expected := []TestStruct{
+		{Name: "Alice", Age: 30, Email: "alice@example.com"},
+		{Name: "Bob", Age: 35, Email: "bob@example.com"},
+	}
+
+	// Validate the parsed records
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %v, Expected: %v", records, expected)
+	}
+}
+
+func TestReadCSVFromFS(t *testing.T) {
+	// Sample CSV data served from an in-memory file system
+	fsys := fstest.MapFS{
+		"fixtures/people.csv": &fstest.MapFile{Data: []byte(`Name,Age,Email
+Alice,30,alice@example.com`)},
+	}
+
+	// Create variables to store records
+	var records []TestStruct
+	handler := func(record interface{}) error {
+		records = append(records, *record.(*TestStruct))
+		return nil
+	}
+
+	// Call ReadCSVFromFS function with the file system and name
+	err := csvutils.ReadCSVFromFS(fsys, "fixtures/people.csv", &TestStruct{}, csvutils.WithHandler(handler))
+	if err != nil {
+		t.Fatalf("ReadCSVFromFS returned error: %v", err)
+	}
+
+	// Validate the parsed records
+	expected := []TestStruct{{Name: "Alice", Age: 30, Email: "alice@example.com"}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %v, Expected: %v", records, expected)
+	}
+
+	// A missing file must be reported
+	if err := csvutils.ReadCSVFromFS(fsys, "missing.csv", &TestStruct{}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got: %v", err)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	// Stream records into an in-memory buffer
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[*TestStruct](&buf)
+
+	if err := encoder.Encode(&TestStruct{Name: "Alice", Age: 30, Email: "alice@example.com"}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if err := encoder.EncodeAll([]*TestStruct{{Name: "Bob", Age: 35, Email: "bob@example.com"}}); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	// Validate the written data
+	expected := "Name,Age,Email\nAlice,30,alice@example.com\nBob,35,bob@example.com\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+}
+
+func TestEncoder_HeaderOnlyOnClose(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[TestStruct](&buf)
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	if buf.String() != "Name,Age,Email\n" {
+		t.Errorf("expected only the header, got: %q", buf.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestEncoder_WriteError(t *testing.T) {
+	encoder := csvutils.NewEncoder[TestStruct](failingWriter{})
+	if err := encoder.Encode(TestStruct{Name: "Alice"}); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	// The buffered write error is reported on flush
+	if err := encoder.Flush(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("expected flush to report the write error, got: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package csvutils
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	// LayoutUnix reads and writes time.Time values as seconds since the Unix epoch.
+	LayoutUnix = "unix"
+	// LayoutUnixMilli reads and writes time.Time values as milliseconds since the Unix epoch.
+	LayoutUnixMilli = "unixmilli"
+
+	defaultTimeLayout = time.RFC3339Nano
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// WithTimeLayout sets the layout used for time.Time fields without a `layout` tag. The
+// layout is a time.Parse layout, LayoutUnix or LayoutUnixMilli; it defaults to
+// time.RFC3339Nano.
+func WithTimeLayout(layout string) func(*csvOptions) {
+	return func(opts *csvOptions) {
+		opts.timeLayout = layout
+	}
+}
+
+// timeLayout returns the layout of a time.Time field, preferring its `layout` tag.
+func timeLayout(field reflect.StructField, opts *csvOptions) string {
+	if layout := field.Tag.Get("layout"); layout != "" {
+		return layout
+	}
+	if opts.timeLayout != "" {
+		return opts.timeLayout
+	}
+	return defaultTimeLayout
+}
+
+func parseTime(s, layout string) (time.Time, error) {
+	switch layout {
+	case LayoutUnix, LayoutUnixMilli:
+		epoch, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error parsing unix time value %s: %w", s, err)
+		}
+		if layout == LayoutUnix {
+			return time.Unix(epoch, 0).UTC(), nil
+		}
+		return time.UnixMilli(epoch).UTC(), nil
+	default:
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("error parsing time value %s: %w", s, err)
+		}
+		return t, nil
+	}
+}
+
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case LayoutUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case LayoutUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.Format(layout)
+	}
+}
+
+func getTimeSetter(fieldType reflect.Type, field reflect.StructField, opts *csvOptions) func(reflect.Value, string) error {
+	switch fieldType {
+	case timeType:
+		layout := timeLayout(field, opts)
+		return func(v reflect.Value, s string) error {
+			if s == "" {
+				v.Set(reflect.ValueOf(time.Time{}))
+				return nil
+			}
+			t, err := parseTime(s, layout)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+	case durationType:
+		return func(v reflect.Value, s string) error {
+			if s == "" {
+				s = "0s"
+			}
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("error parsing duration value %s: %w", s, err)
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+	}
+	return nil
+}
+
+func getTimeFormatter(fieldType reflect.Type, field reflect.StructField, opts *csvOptions) func(reflect.Value) (string, error) {
+	switch fieldType {
+	case timeType:
+		layout := timeLayout(field, opts)
+		return func(v reflect.Value) (string, error) {
+			t := v.Interface().(time.Time)
+			if t.IsZero() {
+				return "", nil
+			}
+			return formatTime(t, layout), nil
+		}
+	case durationType:
+		return func(v reflect.Value) (string, error) {
+			return time.Duration(v.Int()).String(), nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vd09/csvutils"
+)
+
+type Event struct {
+	Name     string        `csv:"name"`
+	Day      time.Time     `csv:"day" layout:"2006-01-02"`
+	Created  *time.Time    `csv:"created"`
+	Epoch    time.Time     `csv:"epoch" layout:"unix"`
+	EpochMs  time.Time     `csv:"epoch_ms" layout:"unixmilli"`
+	Duration time.Duration `csv:"duration"`
+}
+
+func TestTimeFields_RoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 19, 17, 39, 9, 500000000, time.UTC)
+	events := []Event{
+		{
+			Name:     "deploy",
+			Day:      time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC),
+			Created:  &created,
+			Epoch:    time.Unix(1716140349, 0).UTC(),
+			EpochMs:  time.UnixMilli(1716140349123).UTC(),
+			Duration: 90 * time.Second,
+		},
+	}
+
+	// Write the records
+	var buf bytes.Buffer
+	encoder := csvutils.NewEncoder[Event](&buf)
+	if err := encoder.EncodeAll(events); err != nil {
+		t.Fatalf("EncodeAll returned error: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	expected := "name,day,created,epoch,epoch_ms,duration\n" +
+		"deploy,2024-05-19,2024-05-19T17:39:09.5Z,1716140349,1716140349123,1m30s\n"
+	if buf.String() != expected {
+		t.Errorf("Encoded data does not match expected. Got: %q, Expected: %q", buf.String(), expected)
+	}
+
+	// Read them back
+	records, err := csvutils.ReadAll[Event](&buf)
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if !reflect.DeepEqual(records, events) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, events)
+	}
+}
+
+func TestTimeFields_DefaultLayoutOption(t *testing.T) {
+	type Login struct {
+		User string    `csv:"user"`
+		At   time.Time `csv:"at"`
+	}
+	data := "user,at\nalice,19/05/2024 17:39\n"
+
+	records, err := csvutils.ReadAll[Login](strings.NewReader(data), csvutils.WithTimeLayout("02/01/2006 15:04"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+
+	expected := []Login{{User: "alice", At: time.Date(2024, 5, 19, 17, 39, 0, 0, time.UTC)}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+}
+
+func TestTimeFields_InvalidValue(t *testing.T) {
+	data := "name,day,created,epoch,epoch_ms,duration\ndeploy,19.05.2024,,,,\n"
+
+	_, err := csvutils.ReadAll[Event](strings.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), `column "day"`) {
+		t.Errorf("expected an error for the day column, got: %v", err)
+	}
+}
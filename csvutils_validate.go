@@ -0,0 +1,250 @@
+package csvutils
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator is implemented by records that check themselves once all their fields are
+// set. A returned error fails the record like a conversion error.
+type Validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// ValidationError describes a record that was decoded but failed a `validate` tag rule
+// or its Validate method. Use errors.As to retrieve it.
+type ValidationError struct {
+	// Line is the line the record starts on.
+	Line int
+	// Header is the name of the column of the failed field, empty for Validate errors.
+	Header string
+	// FieldName is the name of the failed struct field, empty for Validate errors.
+	FieldName string
+	// Rule is the failed rule, e.g. "min=0", empty for Validate errors.
+	Rule string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	if e.FieldName == "" {
+		return fmt.Sprintf("line %d: validation failed: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %q: field %s failed rule %s: %v", e.Line, e.Header, e.FieldName, e.Rule, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// fieldRule is a parsed rule of a `validate` tag. check receives the field value with
+// pointers dereferenced and the cell text, after defaults are applied.
+type fieldRule struct {
+	rule  string
+	check func(v reflect.Value, cell string) error
+}
+
+// parseValidateTag parses the rules of the `validate` tag, e.g.
+// `validate:"nonempty,min=0,max=150"`. The supported rules are:
+//
+//	nonempty       the cell is not null
+//	min=N, max=N   bounds of numbers, or of the length of strings, slices and maps
+//	len=N          the exact length of strings, slices and maps
+//	oneof=a|b|c    the cell is one of the listed values
+//	email          the cell is an email address
+//	regex=EXPR     the cell matches EXPR; it must be the last rule, as EXPR may
+//	               contain commas
+//
+// Rules other than nonempty pass on null cells.
+func parseValidateTag(field reflect.StructField, fieldType reflect.Type) ([]fieldRule, error) {
+	tag := field.Tag.Get("validate")
+	if tag == "" {
+		return nil, nil
+	}
+	if isNullableType(fieldType) {
+		fieldType = fieldType.Field(0).Type
+	}
+	var rules []fieldRule
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regex=") {
+			rule, tag = tag, ""
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+		check, err := newRuleCheck(rule, fieldType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validate rule %q on field %s: %w", rule, field.Name, err)
+		}
+		rules = append(rules, fieldRule{rule: rule, check: check})
+	}
+	return rules, nil
+}
+
+func newRuleCheck(rule string, fieldType reflect.Type) (func(reflect.Value, string) error, error) {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "nonempty":
+		// Null cells are rejected before any check runs.
+		return func(reflect.Value, string) error { return nil }, nil
+	case "min", "max":
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bound must be a number")
+		}
+		measure, err := ruleMeasure(fieldType, true)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value, _ string) error {
+			switch value := measure(v); {
+			case name == "min" && value < bound:
+				return fmt.Errorf("must be at least %s", arg)
+			case name == "max" && value > bound:
+				return fmt.Errorf("must be at most %s", arg)
+			}
+			return nil
+		}, nil
+	case "len":
+		length, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("length must be an integer")
+		}
+		measure, err := ruleMeasure(fieldType, false)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value, _ string) error {
+			if measure(v) != float64(length) {
+				return fmt.Errorf("must have length %d", length)
+			}
+			return nil
+		}, nil
+	case "oneof":
+		values := strings.Split(arg, "|")
+		return func(_ reflect.Value, cell string) error {
+			for _, value := range values {
+				if cell == value {
+					return nil
+				}
+			}
+			return fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+		}, nil
+	case "email":
+		return func(_ reflect.Value, cell string) error {
+			if address, err := mail.ParseAddress(cell); err != nil || address.Address != cell {
+				return errors.New("must be a valid email address")
+			}
+			return nil
+		}, nil
+	case "regex":
+		expr, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ reflect.Value, cell string) error {
+			if !expr.MatchString(cell) {
+				return fmt.Errorf("must match %s", arg)
+			}
+			return nil
+		}, nil
+	}
+	return nil, errors.New("unknown rule")
+}
+
+// ruleMeasure returns how min, max and len measure a field: numbers by their value, when
+// numeric is set, and strings, slices and maps by their length.
+func ruleMeasure(fieldType reflect.Type, numeric bool) (func(reflect.Value) float64, error) {
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if numeric {
+			return func(v reflect.Value) float64 { return float64(v.Int()) }, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if numeric {
+			return func(v reflect.Value) float64 { return float64(v.Uint()) }, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if numeric {
+			return func(v reflect.Value) float64 { return v.Float() }, nil
+		}
+	case reflect.String:
+		return func(v reflect.Value) float64 { return float64(utf8.RuneCountInString(v.String())) }, nil
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return func(v reflect.Value) float64 { return float64(v.Len()) }, nil
+	}
+	return nil, fmt.Errorf("not supported for type %s", fieldType)
+}
+
+// validate checks the `validate` rules of every field and then the Validator method of
+// the record.
+func (rr *recordReader) validate(recordValue reflect.Value, record []string, line int) error {
+	if err := rr.validateFields(recordValue, rr.fieldInfo, record, line); err != nil {
+		return err
+	}
+	if validator, ok := asInterface(recordValue, validatorType).(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return &ValidationError{Line: line, Err: err}
+		}
+	}
+	return nil
+}
+
+func (rr *recordReader) validateFields(recordValue reflect.Value, infos []fieldInfo, record []string, line int) error {
+	for _, info := range infos {
+		if info.elements != nil {
+			// Only the elements kept by populateRepeated are validated.
+			slice := fieldByIndex(recordValue, info.index)
+			for i := 0; i < slice.Len(); i++ {
+				element := reflect.Indirect(slice.Index(i))
+				if err := rr.validateFields(element, info.elements[i], record, line); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if len(info.rules) == 0 {
+			continue
+		}
+		var cell string
+		if info.columnIndex >= 0 && info.columnIndex < len(record) {
+			cell = record[info.columnIndex]
+		}
+		if cell == "" {
+			cell = info.defaultValue
+		}
+		null := rr.opts.isNull(cell)
+		fieldValue := reflect.Indirect(fieldByIndex(recordValue, info.index))
+		if info.nullable {
+			fieldValue = fieldValue.Field(0)
+		}
+		for _, rule := range info.rules {
+			var err error
+			switch {
+			case rule.rule == "nonempty" && null:
+				err = errors.New("must not be empty")
+			case null:
+				continue
+			default:
+				err = rule.check(fieldValue, cell)
+			}
+			if err != nil {
+				return &ValidationError{
+					Line:      line,
+					Header:    info.columnName,
+					FieldName: info.fieldName,
+					Rule:      rule.rule,
+					Err:       err,
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package csvutils_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vd09/csvutils"
+)
+
+type Member struct {
+	Name  string   `csv:"name" validate:"nonempty,max=10"`
+	Age   int      `csv:"age" validate:"min=0,max=150"`
+	Email string   `csv:"email" validate:"email"`
+	Tier  string   `csv:"tier" validate:"oneof=free|pro"`
+	Code  string   `csv:"code" validate:"len=4,regex=^[A-Z]{2}[0-9]{2}$"`
+	Tags  []string `csv:"tags" validate:"max=2"`
+	Score *int     `csv:"score" validate:"min=1"`
+}
+
+func (m Member) Validate() error {
+	if m.Tier == "pro" && m.Score == nil {
+		return errors.New("pro members need a score")
+	}
+	return nil
+}
+
+func TestValidateTags(t *testing.T) {
+	header := "name,age,email,tier,code,tags,score\n"
+	tests := []struct {
+		name   string
+		row    string
+		header string
+		rule   string
+	}{
+		{"valid", "Ann,30,ann@example.com,free,AB12,a;b,", "", ""},
+		{"nonempty", ",30,ann@example.com,free,AB12,,", "name", "nonempty"},
+		{"max length", "Annabelle Smith,30,ann@example.com,free,AB12,,", "name", "max=10"},
+		{"min", "Ann,-1,ann@example.com,free,AB12,,", "age", "min=0"},
+		{"max", "Ann,151,ann@example.com,free,AB12,,", "age", "max=150"},
+		{"email", "Ann,30,ann(at)example.com,free,AB12,,", "email", "email"},
+		{"oneof", "Ann,30,ann@example.com,gold,AB12,,", "tier", "oneof=free|pro"},
+		{"len", "Ann,30,ann@example.com,free,AB1,,", "code", "len=4"},
+		{"regex", "Ann,30,ann@example.com,free,ab12,,", "code", "regex=^[A-Z]{2}[0-9]{2}$"},
+		{"slice length", "Ann,30,ann@example.com,free,AB12,a;b;c,", "tags", "max=2"},
+		{"pointer", "Ann,30,ann@example.com,free,AB12,,0", "score", "min=1"},
+		{"validator", "Ann,30,ann@example.com,pro,AB12,,", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := csvutils.ReadAll[Member](strings.NewReader(header + test.row + "\n"))
+			if test.name == "valid" {
+				if err != nil {
+					t.Fatalf("ReadAll returned error: %v", err)
+				}
+				return
+			}
+			var validationErr *csvutils.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a ValidationError, got: %v", err)
+			}
+			if validationErr.Line != 2 || validationErr.Header != test.header || validationErr.Rule != test.rule {
+				t.Errorf("ValidationError does not match expected: %+v", validationErr)
+			}
+		})
+	}
+}
+
+func TestValidateTags_ErrorPolicy(t *testing.T) {
+	data := "name,age,email,tier,code,tags,score\n" +
+		"Ann,30,ann@example.com,free,AB12,,\n" +
+		"Bob,200,bob@example.com,free,AB12,,\n"
+
+	// Validation failures are rejected like conversion errors
+	var names []string
+	var rejects bytes.Buffer
+	err := csvutils.ReadCSVFromReader(strings.NewReader(data), &Member{},
+		csvutils.WithHandler(func(record interface{}) error {
+			names = append(names, record.(*Member).Name)
+			return nil
+		}),
+		csvutils.WithErrorPolicy(csvutils.SkipAndWrite), csvutils.WithRejectsWriter(&rejects))
+	if err != nil {
+		t.Fatalf("ReadCSVFromReader returned error: %v", err)
+	}
+	if expected := []string{"Ann"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Handled records do not match expected. Got: %v, Expected: %v", names, expected)
+	}
+	if !strings.HasPrefix(rejects.String(), "error_line,error_column,error_message,name,age") || !strings.HasSuffix(rejects.String(), ",Bob,200,bob@example.com,free,AB12,,\n") {
+		t.Errorf("Rejects do not contain the invalid record: %q", rejects.String())
+	}
+}
+
+func TestValidateTags_Invalid(t *testing.T) {
+	type Invalid struct {
+		Active bool `csv:"active" validate:"min=1"`
+	}
+	_, err := csvutils.ReadAll[Invalid](strings.NewReader("active\ntrue\n"))
+	if err == nil || !strings.Contains(err.Error(), `invalid validate rule "min=1" on field Active`) {
+		t.Errorf("Expected invalid rule error, got: %v", err)
+	}
+}
+
+func TestValidateTags_RepeatedGroups(t *testing.T) {
+	type Stop struct {
+		City string `csv:"city" validate:"nonempty"`
+	}
+	type Route struct {
+		Stops []Stop `csv:"stop" maxlen:"3"`
+	}
+
+	// Dropped trailing groups are not validated
+	records, err := csvutils.ReadAll[Route](strings.NewReader("stop_0_city,stop_1_city,stop_2_city\nBerlin,,\n"))
+	if err != nil {
+		t.Fatalf("ReadAll returned error: %v", err)
+	}
+	if expected := []Route{{Stops: []Stop{{City: "Berlin"}}}}; !reflect.DeepEqual(records, expected) {
+		t.Errorf("Parsed records do not match expected. Got: %+v, Expected: %+v", records, expected)
+	}
+
+	// Kept groups are
+	_, err = csvutils.ReadAll[Route](strings.NewReader("stop_0_city,stop_1_city,stop_2_city\n,Paris,\n"))
+	var validationErr *csvutils.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Header != "stop_0_city" {
+		t.Errorf("Expected a ValidationError for stop_0_city, got: %v", err)
+	}
+}
@@ -1,14 +1,17 @@
 package csvutils
 
 import (
+	"encoding"
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strconv"
 )
 
 // WriteCSV writes a slice of structs to a CSV file at the specified filePath.
-func WriteCSV[T any](filePath string, records []T) error {
+func WriteCSV[T any](filePath string, records []T, options ...func(*csvOptions)) error {
 	if len(records) == 0 {
 		return errors.New("no records to write")
 	}
@@ -20,97 +23,430 @@ func WriteCSV[T any](filePath string, records []T) error {
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	encoder := NewEncoder[T](file, options...)
+	// Appending to an existing file must not repeat the header.
+	encoder.headerWritten = fileExists
+	if err := encoder.EncodeAll(records); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// Encoder writes records of type T as CSV rows to an io.Writer. The header derived from
+// the record struct is written once, before the first record.
+type Encoder[T any] struct {
+	opts          *csvOptions
+	w             io.Writer
+	writer        *csv.Writer
+	bom           bool
+	elemType      reflect.Type
+	columns       []column
+	layout        []int
+	headerWritten bool
+	closed        bool
+}
 
-	elemType := reflect.TypeOf(records[0])
-	if elemType.Kind() == reflect.Ptr {
-		elemType = elemType.Elem()
+// NewEncoder returns an Encoder that writes to w. T must be a struct, a pointer to a
+// struct, or an interface type whose dynamic values are (pointers to) structs.
+func NewEncoder[T any](w io.Writer, options ...func(*csvOptions)) *Encoder[T] {
+	opts := newCsvOptions(options)
+	opts.writing = true
+	return &Encoder[T]{opts: opts, w: w, writer: opts.dialect.newWriter(w), bom: opts.dialect.BOM}
+}
+
+// Encode writes a single record, preceded by the header if it has not been written yet.
+func (e *Encoder[T]) Encode(record T) error {
+	if e.closed {
+		return errors.New("encoder is closed")
+	}
+	recordValue := reflect.ValueOf(record)
+	if recordValue.Kind() == reflect.Ptr {
+		if recordValue.IsNil() {
+			return errors.New("cannot encode nil record")
+		}
+		recordValue = recordValue.Elem()
 	}
-	if elemType.Kind() != reflect.Struct {
+	if recordValue.Kind() != reflect.Struct {
 		return errors.New("records elements must be struct")
 	}
+	if e.elemType == nil {
+		e.elemType = recordValue.Type()
+	}
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
 
-	if !fileExists {
-		headers, err := extractHeaders(elemType, "")
-		if err != nil {
-			return fmt.Errorf("failed to extract headers: %w", err)
-		}
+	recordValues, err := extractValues(recordValue, "", e.opts)
+	if err != nil {
+		return fmt.Errorf("failed to extract values: %w", err)
+	}
+	if err := e.writer.Write(arrange(recordValues, e.layout, e.opts.nullToken())); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
 
-		if err := writer.Write(headers); err != nil {
-			return fmt.Errorf("failed to write header: %w", err)
+// EncodeAll writes all records, stopping at the first error.
+func (e *Encoder[T]) EncodeAll(records []T) error {
+	for _, record := range records {
+		if err := e.Encode(record); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	for _, record := range records {
-		recordValue := reflect.ValueOf(record)
-		if recordValue.Kind() == reflect.Ptr {
-			recordValue = recordValue.Elem()
+// Flush writes any buffered data to the underlying io.Writer and reports any error
+// that occurred during a previous write or flush.
+func (e *Encoder[T]) Flush() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush records: %w", err)
+	}
+	return nil
+}
+
+// Close writes the header if no record was encoded and T is a struct type, then flushes
+// the encoder. The underlying io.Writer is not closed.
+func (e *Encoder[T]) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	if e.elemType == nil {
+		elemType := reflect.TypeOf((*T)(nil)).Elem()
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
 		}
-		recordValues, err := extractValues(recordValue)
-		if err != nil {
-			return fmt.Errorf("failed to extract values: %w", err)
+		if elemType.Kind() == reflect.Struct {
+			e.elemType = elemType
 		}
-		if err := writer.Write(recordValues); err != nil {
-			return fmt.Errorf("failed to write record: %w", err)
+	}
+	if e.elemType != nil {
+		if err := e.writeHeader(); err != nil {
+			return err
 		}
 	}
+	return e.Flush()
+}
+
+// prepare derives the columns of the record type and their layout, once.
+func (e *Encoder[T]) prepare() error {
+	if e.columns != nil {
+		return nil
+	}
+	columns, err := extractColumns(e.elemType, "", e.opts)
+	if err != nil {
+		return fmt.Errorf("failed to extract headers: %w", err)
+	}
+	markShadowed(columns)
+	var layout []int
+	if e.opts.header != nil {
+		layout, err = headerLayout(columns, e.opts.header, e.opts)
+	} else {
+		layout, err = columnLayout(columns)
+	}
+	if err != nil {
+		return err
+	}
+	e.columns, e.layout = columns, layout
+	return nil
+}
 
+func (e *Encoder[T]) writeHeader() error {
+	if err := e.prepare(); err != nil {
+		return err
+	}
+	if e.headerWritten {
+		return nil
+	}
+	if e.bom {
+		// Nothing has been buffered by the csv.Writer yet, so the mark goes first.
+		if _, err := e.w.Write(utf8BOM); err != nil {
+			return fmt.Errorf("failed to write byte order mark: %w", err)
+		}
+	}
+	e.headerWritten = true
+	if e.opts.noHeader {
+		return nil
+	}
+	headers := e.opts.header
+	if headers == nil {
+		headers = make([]string, len(e.columns))
+		for i, column := range e.columns {
+			headers[i] = column.name
+		}
+		headers = arrange(headers, e.layout, "")
+	}
+	if err := e.writer.Write(headers); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
 	return nil
 }
 
 // extractHeaders extracts CSV headers from a struct type, including nested structs.
-func extractHeaders(t reflect.Type, prefix string) ([]string, error) {
-	var headers []string
+func extractHeaders(t reflect.Type, prefix string, opts *csvOptions) ([]string, error) {
+	columns, err := extractColumns(t, prefix, opts)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.name
+	}
+	return headers, nil
+}
+
+// column is a column derived from a struct type, in the order of extractValues.
+type column struct {
+	name string
+	// index is the position set by the index tag of the field, or -1.
+	index int
+	// depth is the number of structs the field is nested in.
+	depth int
+	// shadowed is set for columns hidden by another column of the same name, which are
+	// not written.
+	shadowed bool
+}
+
+func extractColumns(t reflect.Type, prefix string, opts *csvOptions) ([]column, error) {
+	var columns []column
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		csvTag := field.Tag.Get("csv")
-		if csvTag == "" {
-			csvTag = field.Name
+		if skipField(field, opts) {
+			continue
 		}
-		headerName := prefix + csvTag
+		headerName := prefix + columnName(field)
 		fieldType := field.Type
 		if fieldType.Kind() == reflect.Ptr {
 			fieldType = fieldType.Elem()
 		}
-		if fieldType.Kind() == reflect.Struct {
-			nestedHeaders, err := extractHeaders(fieldType, headerName+"_")
+		if groupType, ok := repeatedGroupType(field.Type, opts); ok {
+			groupColumns, err := extractRepeatedColumns(field, groupType, headerName, opts)
+			if err != nil {
+				return nil, err
+			}
+			for _, groupColumn := range groupColumns {
+				groupColumn.index = -1
+				groupColumn.depth++
+				columns = append(columns, groupColumn)
+			}
+		} else if fieldType.Kind() == reflect.Struct && !isLeafType(fieldType, opts) {
+			nestedColumns, err := extractColumns(fieldType, opts.nestedPrefix(prefix, field), opts)
 			if err != nil {
 				return nil, err
 			}
-			headers = append(headers, nestedHeaders...)
+			for _, nestedColumn := range nestedColumns {
+				nestedColumn.depth++
+				columns = append(columns, nestedColumn)
+			}
 		} else {
-			headers = append(headers, headerName)
+			index := -1
+			if tag := parseTag(field); tag.index != "" {
+				position, err := strconv.Atoi(tag.index)
+				if err != nil || position < 0 {
+					return nil, fmt.Errorf("invalid index tag %q on field %s", tag.index, field.Name)
+				}
+				index = position
+			}
+			columns = append(columns, column{name: headerName, index: index})
 		}
 	}
-	return headers, nil
+	return columns, nil
+}
+
+// markShadowed marks the columns hidden by another column of the same name, so the
+// writer drops the same fields the reader does.
+func markShadowed(columns []column) {
+	names := make([]string, len(columns))
+	depths := make([]int, len(columns))
+	for i, column := range columns {
+		names[i], depths[i] = column.name, column.depth
+	}
+	for i, shadowed := range shadowedColumns(names, depths) {
+		columns[i].shadowed = shadowed
+	}
+}
+
+// columnLayout returns, for every output position, the index of the value written there
+// or -1 for a gap. Fields with an index tag are written at their position and the other
+// fields follow the last of them in field order. The layout is nil when no field has an
+// index tag or is shadowed and values are written in field order.
+func columnLayout(columns []column) ([]int, error) {
+	last := -1
+	shadowed := false
+	for _, column := range columns {
+		last = max(last, column.index)
+		shadowed = shadowed || column.shadowed
+	}
+	if last < 0 && !shadowed {
+		return nil, nil
+	}
+	layout := make([]int, last+1)
+	for i := range layout {
+		layout[i] = -1
+	}
+	for i, column := range columns {
+		switch {
+		case column.shadowed:
+		case column.index < 0:
+			layout = append(layout, i)
+		case layout[column.index] >= 0:
+			return nil, fmt.Errorf("columns %s and %s both have index %d", columns[layout[column.index]].name, column.name, column.index)
+		default:
+			layout[column.index] = i
+		}
+	}
+	return layout, nil
+}
+
+// headerLayout places the columns under the header supplied with WithHeader, matching
+// names like the reader does. Fields with an index tag keep their position and fields
+// missing from the header are not written.
+func headerLayout(columns []column, header []string, opts *csvOptions) ([]int, error) {
+	layout := make([]int, len(header))
+	for i := range layout {
+		layout[i] = -1
+	}
+	for i, column := range columns {
+		if column.index < 0 || column.shadowed {
+			continue
+		}
+		if column.index >= len(header) {
+			return nil, fmt.Errorf("index %d of column %s is outside the header", column.index, column.name)
+		}
+		if layout[column.index] >= 0 {
+			return nil, fmt.Errorf("columns %s and %s both have index %d", columns[layout[column.index]].name, column.name, column.index)
+		}
+		layout[column.index] = i
+	}
+	for position, name := range header {
+		if layout[position] >= 0 {
+			continue
+		}
+		for i, column := range columns {
+			if column.index < 0 && !column.shadowed && opts.headerMatcher(column.name) == opts.headerMatcher(name) {
+				layout[position] = i
+				break
+			}
+		}
+		if layout[position] < 0 {
+			return nil, fmt.Errorf("header column %q does not match any field", name)
+		}
+	}
+	return layout, nil
+}
+
+// arrange reorders values by layout, filling gaps with fill.
+func arrange(values []string, layout []int, fill string) []string {
+	if layout == nil {
+		return values
+	}
+	arranged := make([]string, len(layout))
+	for i, index := range layout {
+		if index < 0 {
+			arranged[i] = fill
+		} else {
+			arranged[i] = values[index]
+		}
+	}
+	return arranged
 }
 
 // extractValues extracts field values from a struct, including nested structs.
-func extractValues(v reflect.Value) ([]string, error) {
+func extractValues(v reflect.Value, prefix string, opts *csvOptions) ([]string, error) {
 	var values []string
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
+		structField := v.Type().Field(i)
+		if skipField(structField, opts) {
+			continue
+		}
+		headerName := prefix + columnName(structField)
+		fieldType := structField.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if groupType, ok := repeatedGroupType(structField.Type, opts); ok {
+			groupValues, err := extractRepeatedValues(field, structField, groupType, headerName, opts)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, groupValues...)
+			continue
+		}
+		isNested := fieldType.Kind() == reflect.Struct && !isLeafType(fieldType, opts)
 		if field.Kind() == reflect.Ptr {
 			if field.IsNil() {
-				// Handle nil pointer by adding empty values for each field in the struct
-				fieldType := field.Type().Elem()
-				for j := 0; j < fieldType.NumField(); j++ {
-					values = append(values, "")
+				// Handle nil pointer by adding a null value for every column of the field
+				columns := 1
+				if isNested {
+					nestedHeaders, err := extractHeaders(fieldType, "", opts)
+					if err != nil {
+						return nil, err
+					}
+					columns = len(nestedHeaders)
+				}
+				for j := 0; j < columns; j++ {
+					values = append(values, opts.nullToken())
 				}
 				continue
 			}
 			field = field.Elem()
 		}
-		if field.Kind() == reflect.Struct {
-			nestedValues, err := extractValues(field)
+		if isNested {
+			nestedValues, err := extractValues(field, opts.nestedPrefix(prefix, structField), opts)
 			if err != nil {
 				return nil, err
 			}
 			values = append(values, nestedValues...)
+		} else if parseTag(structField).omitEmpty && field.IsZero() {
+			values = append(values, opts.nullToken())
 		} else {
-			values = append(values, fmt.Sprintf("%v", field.Interface()))
+			value, err := formatField(field, fieldType, structField, headerName, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to format field %s: %w", structField.Name, err)
+			}
+			values = append(values, value)
 		}
 	}
 	return values, nil
 }
+
+// formatField converts a single field value to its CSV representation.
+func formatField(v reflect.Value, fieldType reflect.Type, field reflect.StructField, column string, opts *csvOptions) (string, error) {
+	if formatter := getConverterFormatter(fieldType, opts); formatter != nil {
+		return formatter(v)
+	}
+	if isNullableType(fieldType) {
+		if !v.Field(1).Bool() {
+			return opts.nullToken(), nil
+		}
+		return formatField(v.Field(0), v.Field(0).Type(), field, column, opts)
+	}
+	if v.Kind() == reflect.Interface && v.IsNil() {
+		return opts.nullToken(), nil
+	}
+	if marshaler, ok := asInterface(v, csvMarshalerType).(CSVMarshaler); ok {
+		return marshaler.MarshalCSV(column)
+	}
+	if formatter := getTimeFormatter(fieldType, field, opts); formatter != nil {
+		return formatter(v)
+	}
+	if formatter := getBigFormatter(fieldType); formatter != nil {
+		return formatter(v)
+	}
+	if marshaler, ok := asInterface(v, textMarshalerType).(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+	switch {
+	case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Uint8:
+		return encodeBytes(v.Bytes(), bytesEncoding(field))
+	case fieldType.Kind() == reflect.Slice:
+		return formatSlice(v, field, column, opts)
+	case fieldType.Kind() == reflect.Map:
+		return formatMap(v, field, column, opts)
+	}
+	return fmt.Sprintf("%v", v.Interface()), nil
+}
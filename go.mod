@@ -1,6 +1,6 @@
 module github.com/vd09/csvutils
 
-go 1.21.5
+go 1.23
 
 require (
 	github.com/vd09/gr-variable v0.0.0-20240505213543-579df24f059a // indirect